@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 type BackendHTTPSettings struct {
@@ -23,9 +24,6 @@ type BackendHTTPSettings struct {
 		} `json:"probe"`
 		Protocol                       string      `json:"protocol,omitempty"`
 		RequestTimeout int    `json:"requestTimeout,omitempty"`
-
-		//we don't use these ones. They will not appear in this current implementation
-		//I there is someone interested in these params, (s)he should implement them in accordance with Backend_http_settings struct
 		AuthenticationCertificates     *[]struct { ////ajouté
 			ID string `json:"id"`
 		} `json:"authenticationCertificates"`
@@ -57,8 +55,30 @@ type Backend_http_settings struct {
 	Protocol                       		types.String	`tfsdk:"protocol"`								
 	Request_timeout						types.Int64		`tfsdk:"request_timeout"`						
 	Probe_name							types.String	`tfsdk:"probe_name"`							
+	//optional
+	Host_name							types.String	`tfsdk:"host_name"`
+	Path								types.String	`tfsdk:"path"`
+	Connection_draining					*Connection_draining	`tfsdk:"connection_draining"`
+	Trusted_root_certificate_names		[]types.String	`tfsdk:"trusted_root_certificate_names"`
+	Authentication_certificate_names	[]types.String	`tfsdk:"authentication_certificate_names"`
+}
+
+type Connection_draining struct {
+	//required
+	Enabled								types.Bool		`tfsdk:"enabled"`
+	Drain_timeout_sec					types.Int64		`tfsdk:"drain_timeout_sec"`
 }
 
+// the bounds of the connection draining timeout accepted by Azure (in seconds)
+const minDrainTimeoutSec = 1
+const maxDrainTimeoutSec = 3600
+
+// the bounds of the request timeout accepted by Azure (in seconds)
+const minRequestTimeout = 1
+const maxRequestTimeout = 86400
+
+var cookieBasedAffinities = []string{"Enabled", "Disabled"}
+
 func createBackendHTTPSettings(backend_plan Backend_http_settings, AZURE_SUBSCRIPTION_ID string, 
 								rg_name string, agw_name string) BackendHTTPSettings{
 	backend_json := BackendHTTPSettings{
@@ -91,6 +111,8 @@ func createBackendHTTPSettings(backend_plan Backend_http_settings, AZURE_SUBSCRI
 			Port: 							int(backend_plan.Port.Value),
 			Protocol: 						backend_plan.Protocol.Value,
 			RequestTimeout: 				int(backend_plan.Request_timeout.Value),
+			HostName: 						backend_plan.Host_name.Value,
+			Path: 							backend_plan.Path.Value,
 		},
 		Type: "Microsoft.Network/applicationGateways/backendHttpSettingsCollection",
 	}
@@ -109,6 +131,31 @@ func createBackendHTTPSettings(backend_plan Backend_http_settings, AZURE_SUBSCRI
 			ID: probe_string + backend_plan.Probe_name.Value,
 		}		
 	}	
+	if backend_plan.Connection_draining != nil {
+		backend_json.Properties.ConnectionDraining = &struct{
+			DrainTimeoutInSec int "json:\"drainTimeoutInSec,omitempty\""; 
+			Enabled bool "json:\"enabled,omitempty\""
+		}{
+			DrainTimeoutInSec: int(backend_plan.Connection_draining.Drain_timeout_sec.Value),
+			Enabled: backend_plan.Connection_draining.Enabled.Value,
+		}
+	}
+	//the trusted root certificates (v2 SKUs) and the authentication certificates (v1 SKUs) are referenced by name
+	certificate_string := "/subscriptions/"+AZURE_SUBSCRIPTION_ID+"/resourceGroups/"+rg_name+"/providers/Microsoft.Network/applicationGateways/"+agw_name
+	if len(backend_plan.Trusted_root_certificate_names) != 0 {
+		trustedRootCertificates := make([]struct{ID string "json:\"id,omitempty\""}, len(backend_plan.Trusted_root_certificate_names))
+		for i := 0; i < len(backend_plan.Trusted_root_certificate_names); i++ {
+			trustedRootCertificates[i].ID = certificate_string+"/trustedRootCertificates/"+backend_plan.Trusted_root_certificate_names[i].Value
+		}
+		backend_json.Properties.TrustedRootCertificates = &trustedRootCertificates
+	}
+	if len(backend_plan.Authentication_certificate_names) != 0 {
+		authenticationCertificates := make([]struct{ID string "json:\"id\""}, len(backend_plan.Authentication_certificate_names))
+		for i := 0; i < len(backend_plan.Authentication_certificate_names); i++ {
+			authenticationCertificates[i].ID = certificate_string+"/authenticationCertificates/"+backend_plan.Authentication_certificate_names[i].Value
+		}
+		backend_json.Properties.AuthenticationCertificates = &authenticationCertificates
+	}
 	
 	return backend_json
 }
@@ -143,8 +190,48 @@ func generateBackendHTTPSettingsState(gw ApplicationGateway, BackendHTTPSettings
 	}else{
 		backend_state.Affinity_cookie_name.Null = true
 	}
+	if backend_json.Properties.HostName != "" {
+		backend_state.Host_name = types.String{Value: backend_json.Properties.HostName}
+	}else{
+		backend_state.Host_name = types.String{Null: true}
+	}
+	if backend_json.Properties.Path != "" {
+		backend_state.Path = types.String{Value: backend_json.Properties.Path}
+	}else{
+		backend_state.Path = types.String{Null: true}
+	}
+	if backend_json.Properties.ConnectionDraining != nil {
+		backend_state.Connection_draining = &Connection_draining{
+			Enabled:           types.Bool{Value: backend_json.Properties.ConnectionDraining.Enabled},
+			Drain_timeout_sec: types.Int64{Value: int64(backend_json.Properties.ConnectionDraining.DrainTimeoutInSec)},
+		}
+	}
+	//map the certificate names. the names are the last part of the IDs
+	if backend_json.Properties.TrustedRootCertificates != nil && len(*backend_json.Properties.TrustedRootCertificates) != 0 {
+		trustedRootCertificates := *backend_json.Properties.TrustedRootCertificates
+		backend_state.Trusted_root_certificate_names = make([]types.String, len(trustedRootCertificates))
+		for i := 0; i < len(trustedRootCertificates); i++ {
+			splitted_list := strings.Split(trustedRootCertificates[i].ID,"/")
+			backend_state.Trusted_root_certificate_names[i] = types.String{Value: splitted_list[len(splitted_list)-1]}
+		}
+	}
+	if backend_json.Properties.AuthenticationCertificates != nil && len(*backend_json.Properties.AuthenticationCertificates) != 0 {
+		authenticationCertificates := *backend_json.Properties.AuthenticationCertificates
+		backend_state.Authentication_certificate_names = make([]types.String, len(authenticationCertificates))
+		for i := 0; i < len(authenticationCertificates); i++ {
+			splitted_list := strings.Split(authenticationCertificates[i].ID,"/")
+			backend_state.Authentication_certificate_names[i] = types.String{Value: splitted_list[len(splitted_list)-1]}
+		}
+	}
 	return backend_state
 }
+func getConnectionDrainingState(connectionDraining_state *Connection_draining, connectionDraining_prior *Connection_draining) *Connection_draining {
+	//Azure returns a disabled connection draining when it is not configured, in this case the block stays null to avoid a diff
+	if connectionDraining_prior == nil && connectionDraining_state != nil && !connectionDraining_state.Enabled.Value {
+		return nil
+	}
+	return connectionDraining_state
+}
 func getBackendHTTPSettingsElementKey(gw ApplicationGateway, BackendHTTPSettingsName string) int {
 	key := -1
 	for i := len(gw.Properties.BackendHTTPSettingsCollection) - 1; i >= 0; i-- {
@@ -170,6 +257,78 @@ func removeBackendHTTPSettingsElement(gw *ApplicationGateway, BackendHTTPSetting
 		}
 	}
 }
+func checkAuthenticationCertificateElement(gw ApplicationGateway, AuthenticationCertificateName string) bool {
+	exist := false
+	for i := len(gw.Properties.AuthenticationCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.AuthenticationCertificates[i].Name == AuthenticationCertificateName {
+			exist = true
+		}
+	}
+	return exist
+}
+func checkBackendHTTPSettingsConfig(backend_plan Backend_http_settings, gw ApplicationGateway) (string, bool) {
+	//return the error message when the backend http settings don't satisfy the constraints, and true
+	if len(backend_plan.Trusted_root_certificate_names) != 0 && !checkApplicationGatewayV2(gw) {
+		return "In Backend_http_settings "+backend_plan.Name.Value+", the trusted root certificates can't be used because the tier of the gateway ("+
+			gw.Properties.Sku.Tier+") is not a v2 one (Standard_v2 or WAF_v2). ", true
+	}
+	if len(backend_plan.Authentication_certificate_names) != 0 && checkApplicationGatewayV2(gw) {
+		return "In Backend_http_settings "+backend_plan.Name.Value+", the authentication certificates are only supported by the v1 SKUs, "+
+			"use trusted_root_certificate_names with the tier of the gateway ("+gw.Properties.Sku.Tier+"). ", true
+	}
+	//the certificates are not managed by the binding, so they have to exist in the gateway
+	for _, name := range backend_plan.Trusted_root_certificate_names {
+		if !checkTrustedRootCertificateElement(gw, name.Value) {
+			return "In Backend_http_settings "+backend_plan.Name.Value+", the trusted root certificate ("+name.Value+") doesn't exist in the gateway. ", true
+		}
+	}
+	for _, name := range backend_plan.Authentication_certificate_names {
+		if !checkAuthenticationCertificateElement(gw, name.Value) {
+			return "In Backend_http_settings "+backend_plan.Name.Value+", the authentication certificate ("+name.Value+") doesn't exist in the gateway. ", true
+		}
+	}
+	return "", false
+}
+func validateBackendHTTPSettingsConfig(backend Backend_http_settings, resp *tfsdk.ValidateResourceConfigResponse) {
+	//check the constraints between the attributes of the backend http settings
+	path := tftypes.NewAttributePath().WithAttributeName("backend_http_settings")
+	if backend.Cookie_based_affinity.Value == "Enabled" && checkStringMissing(backend.Affinity_cookie_name) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("affinity_cookie_name"),
+			"Invalid Backend_http_settings: "+backend.Name.Value+", affinity_cookie_name is required when cookie_based_affinity is Enabled.",
+			"Please, add affinity_cookie_name then retry.",
+		)
+	}
+	if checkStringConfigured(backend.Host_name) && backend.Pick_host_name_from_backend_address.Value {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("host_name"),
+			"Invalid Backend_http_settings: "+backend.Name.Value+", host_name and pick_host_name_from_backend_address are mutually exclusive. "+
+			"Only one should be set.",
+			"Please, remove host_name or disable pick_host_name_from_backend_address then retry.",
+		)
+	}
+	if checkStringConfigured(backend.Path) && !strings.HasPrefix(backend.Path.Value, "/") {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("path"),
+			"Invalid Backend_http_settings: "+backend.Name.Value+", the path ("+backend.Path.Value+") has to start with \"/\".",
+			"Please, change the path then retry.",
+		)
+	}
+	if !backend.Protocol.Unknown && !strings.EqualFold(backend.Protocol.Value, "https") {
+		for _, certificates := range []struct {
+			attribute	string
+			names		[]types.String
+		}{
+			{"trusted_root_certificate_names", backend.Trusted_root_certificate_names},
+			{"authentication_certificate_names", backend.Authentication_certificate_names},
+		} {
+			attribute := certificates.attribute
+			if len(certificates.names) != 0 {
+				resp.Diagnostics.AddAttributeError(path.WithAttributeName(attribute),
+					"Invalid Backend_http_settings: "+backend.Name.Value+", "+attribute+" can only be used with the Https protocol.",
+					"Please, remove "+attribute+" or change the protocol then retry.",
+				)
+			}
+		}
+	}
+}
 func checkBackendHTTPSettingsCreate(plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
 	if plan.Backend_http_settings.Probe_name.Value != "" {
 		if plan.Backend_http_settings.Probe_name.Value != plan.Probe.Name.Value {
@@ -181,6 +340,13 @@ func checkBackendHTTPSettingsCreate(plan BindingService, gw ApplicationGateway,
 			return true
 		}
 	}
+	if err, fail := checkBackendHTTPSettingsConfig(plan.Backend_http_settings, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. "+err,
+			"Please, change Backend_http_settings configuration then retry.",
+		)
+		return true
+	}
 	return false
 }
 func checkBackendHTTPSettingsUpdate(plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
@@ -195,5 +361,12 @@ func checkBackendHTTPSettingsUpdate(plan BindingService, gw ApplicationGateway,
 			return true
 		}
 	}
+	if err, fail := checkBackendHTTPSettingsConfig(plan.Backend_http_settings, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. "+err,
+			"Please, change Backend_http_settings configuration then retry.",
+		)
+		return true
+	}
 	return false
 }
\ No newline at end of file
@@ -1,10 +1,14 @@
 package azurermagw
 
 import (
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 type HTTPListener struct {
@@ -46,12 +50,23 @@ type Http_listener struct {
 	//optional
 	Require_sni 						types.Bool		`tfsdk:"require_sni"`	//default to false
 	Ssl_certificate_name 				types.String	`tfsdk:"ssl_certificate_name"`							
-	//Ssl_profile_name 
-	//Firewall_policy_id 
-	//Custom_error_configuration 
+	Ssl_profile_name					types.String	`tfsdk:"ssl_profile_name"`
+	Firewall_policy_id					types.String	`tfsdk:"firewall_policy_id"`
+	Custom_error_configuration			[]Custom_error_configuration	`tfsdk:"custom_error_configuration"`
+}
 
+type Custom_error_configuration struct {
+	//required
+	Status_code							types.String	`tfsdk:"status_code"`
+	Custom_error_page_url				types.String	`tfsdk:"custom_error_page_url"`
 }
 
+// the status codes for which a listener can have its own error page
+var customErrorStatusCodes = []string{"HttpStatus403", "HttpStatus502"}
+
+// the ARM ID of a WAF policy: /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Network/ApplicationGatewayWebApplicationFirewallPolicies/{name}
+var firewallPolicyIDRegexp = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Network/ApplicationGatewayWebApplicationFirewallPolicies/[^/]+$`)
+
 func createHTTPListener(httpListener_plan *Http_listener, AZURE_SUBSCRIPTION_ID string, 
 								rg_name string, agw_name string) (HTTPListener){
 	httpListener_json := HTTPListener{
@@ -118,6 +133,13 @@ func createHTTPListener(httpListener_plan *Http_listener, AZURE_SUBSCRIPTION_ID
 		}		
 	}
 	
+	//ssl profile is optional and only used by https listeners
+	if httpListener_plan.Ssl_profile_name.Value != "" {
+		sslProfileID := "/subscriptions/"+AZURE_SUBSCRIPTION_ID+"/resourceGroups/"+rg_name+
+		"/providers/Microsoft.Network/applicationGateways/"+agw_name+"/sslProfiles/"+httpListener_plan.Ssl_profile_name.Value
+		httpListener_json.Properties.SslProfile = &struct{ID string "json:\"id,omitempty\""}{ID: sslProfileID,}
+	}
+	
 	//verify the mutual exclusivity of the optional attributes hostname and hostnames
 	//var error_Hostname string
 	if httpListener_plan.Host_name.Value != "" {
@@ -131,6 +153,22 @@ func createHTTPListener(httpListener_plan *Http_listener, AZURE_SUBSCRIPTION_ID
 			httpListener_json.Properties.HostNames[i] = httpListener_plan.Host_names[i].Value
 		}
 	}
+	//firewall policy id is optional
+	if httpListener_plan.Firewall_policy_id.Value != "" {
+		httpListener_json.Properties.FirewallPolicy = &struct{ID string "json:\"id,omitempty\""}{ID: httpListener_plan.Firewall_policy_id.Value,}
+	}
+	//custom error configurations are optional
+	if len(httpListener_plan.Custom_error_configuration) != 0 {
+		customErrorConfigurations := make([]struct{
+			CustomErrorPageURL string "json:\"customErrorPageUrl,omitempty\""; 
+			StatusCode string "json:\"statusCode,omitempty\""
+		}, len(httpListener_plan.Custom_error_configuration))
+		for i := 0; i < len(httpListener_plan.Custom_error_configuration); i++ {
+			customErrorConfigurations[i].CustomErrorPageURL = httpListener_plan.Custom_error_configuration[i].Custom_error_page_url.Value
+			customErrorConfigurations[i].StatusCode = httpListener_plan.Custom_error_configuration[i].Status_code.Value
+		}
+		httpListener_json.Properties.CustomErrorConfigurations = &customErrorConfigurations
+	}
 	return httpListener_json
 }
 func generateHTTPListenerState(gw ApplicationGateway, HTTPListenerName string) Http_listener {
@@ -194,6 +232,34 @@ func generateHTTPListenerState(gw ApplicationGateway, HTTPListenerName string) H
 		httpListener_state.Ssl_certificate_name = types.String{Null: true}
 	}
 
+	//map Ssl_profile_name
+	//split the Ssl_profile_name ID using the separator "/". the Ssl_profile_name name is the last one
+	if httpListener_json.Properties.SslProfile != nil && httpListener_json.Properties.SslProfile.ID != "" {
+		splitted_list := strings.Split(httpListener_json.Properties.SslProfile.ID,"/")
+		httpListener_state.Ssl_profile_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}else{
+		httpListener_state.Ssl_profile_name = types.String{Null: true}
+	}
+
+	//map Firewall_policy_id. the whole ID is kept
+	if httpListener_json.Properties.FirewallPolicy != nil && httpListener_json.Properties.FirewallPolicy.ID != "" {
+		httpListener_state.Firewall_policy_id = types.String{Value: httpListener_json.Properties.FirewallPolicy.ID}
+	}else{
+		httpListener_state.Firewall_policy_id = types.String{Null: true}
+	}
+
+	//map Custom_error_configuration. it stays null when the listener has no custom error page
+	if httpListener_json.Properties.CustomErrorConfigurations != nil && len(*httpListener_json.Properties.CustomErrorConfigurations) != 0 {
+		customErrorConfigurations := *httpListener_json.Properties.CustomErrorConfigurations
+		httpListener_state.Custom_error_configuration = make([]Custom_error_configuration, len(customErrorConfigurations))
+		for i := 0; i < len(customErrorConfigurations); i++ {
+			httpListener_state.Custom_error_configuration[i] = Custom_error_configuration{
+				Status_code:           types.String{Value: customErrorConfigurations[i].StatusCode},
+				Custom_error_page_url: types.String{Value: customErrorConfigurations[i].CustomErrorPageURL},
+			}
+		}
+	}
+
 	return httpListener_state
 }
 func getHTTPListenerElementKey_gw(gw ApplicationGateway, HTTPListenerName string) int {
@@ -270,22 +336,6 @@ func removeHTTPListenerElement(gw *ApplicationGateway, HTTPListenerName string)
 	}
 }
 func checkHTTPListenerCreate(http_listener Http_listener, plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
-	if http_listener.Ssl_certificate_name.Value != "" && strings.EqualFold(http_listener.Protocol.Value,"http") {
-		//no need for SslCertificate
-		resp.Diagnostics.AddError(
-		"Unable to create binding. A SslCertificate name ("+http_listener.Ssl_certificate_name.Value+") is declared in Http_listener: "+ 
-		http_listener.Name.Value+". There is no need for SslCertificate name in this case. ",
-		"Please, change Http listener configuration then retry.",)
-		return true
-	}
-	if http_listener.Ssl_certificate_name.Value == "" && strings.EqualFold(http_listener.Protocol.Value,"https") {
-		//no need for SslCertificate
-		resp.Diagnostics.AddError(
-		"Unable to create binding. A SslCertificate name is missing for the Http_listener: "+ 
-		http_listener.Name.Value+".",
-		"Please, change Http listener configuration then retry.",)
-		return true
-	}
 	//if it's about https, check if the certificate name match the one declared un the binding service, or (coming soon) in the gw
 	if http_listener.Ssl_certificate_name.Value != "" &&
 		http_listener.Ssl_certificate_name.Value != plan.Ssl_certificate.Name.Value{
@@ -296,41 +346,15 @@ func checkHTTPListenerCreate(http_listener Http_listener, plan BindingService, g
 		"Please, change Ssl Certificate name then retry.",)
 		return true
 	}
-	if http_listener.Host_name.Value != "" && len(http_listener.Host_names) != 0 {
-		//hostname and hostnames are mutually exclusive. only one should be set
+	if err, fail := checkHTTPListenerSslProfile(http_listener, plan, gw); fail {
 		resp.Diagnostics.AddError(
-			"Unable to create binding. In HTTPS Listener "+ http_listener.Name.Value+", Hostname and Hostnames are mutually exclusive. "+
-			"Only one should be set",
-			"Please, change HTTP Listener configuration then retry.",)
-		return true
-	}
-	if http_listener.Host_name.Value == "" && len(http_listener.Host_names) == 0 {
-		//hostname and hostnames are mutually exclusive. at least and only one should be set
-		resp.Diagnostics.AddError(
-			"Unable to create binding. In HTTP Listener "+ http_listener.Name.Value+", both Hostname and Hostnames are missing. "+
-			"At least and only one should be set",
-			"Please, change HTTP Listener configuration then retry.",)
+			"Unable to create binding. In HTTP Listener "+ http_listener.Name.Value+", "+err,
+			"Please, change HTTP Listener ssl profile name then retry.",)
 		return true
 	}
 	return false
 }
 func checkHTTPListenerUpdate(http_listener Http_listener, plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
-	if http_listener.Ssl_certificate_name.Value != "" && strings.EqualFold(http_listener.Protocol.Value,"http") {
-		//no need for SslCertificate
-		resp.Diagnostics.AddError(
-		"Unable to update binding. A SslCertificate name ("+http_listener.Ssl_certificate_name.Value+") is declared in Http_listener: "+ 
-		http_listener.Name.Value+". There is no need for SslCertificate name in this case. ",
-		"Please, change Http listener configuration then retry.",)
-		return true
-	}
-	if http_listener.Ssl_certificate_name.Value == "" && strings.EqualFold(http_listener.Protocol.Value,"https") {
-		//no need for SslCertificate
-		resp.Diagnostics.AddError(
-		"Unable to update binding. A SslCertificate name is missing for the Http_listener: "+ 
-		http_listener.Name.Value+".",
-		"Please, change Http listener configuration then retry.",)
-		return true
-	}
 	//if it's about https, check if the certificate name match the one declared un the binding service, or in the gw
 	if http_listener.Ssl_certificate_name.Value != "" &&
 		http_listener.Ssl_certificate_name.Value != plan.Ssl_certificate.Name.Value{
@@ -341,24 +365,94 @@ func checkHTTPListenerUpdate(http_listener Http_listener, plan BindingService, g
 		"Please, change Ssl Certificate name then retry.",)
 		return true
 	}
-	if http_listener.Host_name.Value != "" && len(http_listener.Host_names) != 0 {
-		//hostname and hostnames are mutually exclusive. only one should be set
+	if err, fail := checkHTTPListenerSslProfile(http_listener, plan, gw); fail {
 		resp.Diagnostics.AddError(
-			"Unable to update binding. In HTTPS Listener "+ http_listener.Name.Value+", Hostname and Hostnames are mutually exclusive. "+
-			"Only one should be set",
-			"Please, change HTTP Listener configuration then retry.",)
-		return true
-	}
-	if http_listener.Host_name.Value == "" && len(http_listener.Host_names) == 0 {
-		//hostname and hostnames are mutually exclusive. at least and only one should be set
-		resp.Diagnostics.AddError(
-			"Unable to update binding. In HTTP Listener "+ http_listener.Name.Value+", both Hostname and Hostnames are missing. "+
-			"At least and only one should be set",
-			"Please, change HTTP Listener configuration then retry.",)
+			"Unable to update binding. In HTTP Listener "+ http_listener.Name.Value+", "+err,
+			"Please, change HTTP Listener ssl profile name then retry.",)
 		return true
 	}
 	return false
 }
+func validateHTTPListenerConfig(key string, http_listener Http_listener, resp *tfsdk.ValidateResourceConfigResponse) {
+	//check the constraints between the attributes of the listener, the values coming from other resources are checked once known
+	path := tftypes.NewAttributePath().WithAttributeName("http_listeners").WithElementKeyString(key)
+	if !http_listener.Protocol.Unknown {
+		if checkStringConfigured(http_listener.Ssl_certificate_name) && strings.EqualFold(http_listener.Protocol.Value,"http") {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("ssl_certificate_name"),
+				"Invalid Http_listener: "+http_listener.Name.Value+", a SslCertificate name ("+http_listener.Ssl_certificate_name.Value+
+				") can't be declared for the Http protocol.",
+				"Please, remove the ssl_certificate_name or change the protocol then retry.",
+			)
+		}
+		if checkStringMissing(http_listener.Ssl_certificate_name) && strings.EqualFold(http_listener.Protocol.Value,"https") {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("ssl_certificate_name"),
+				"Invalid Http_listener: "+http_listener.Name.Value+", a SslCertificate name is required for the Https protocol.",
+				"Please, add the ssl_certificate_name or change the protocol then retry.",
+			)
+		}
+		if checkStringConfigured(http_listener.Ssl_profile_name) && !strings.EqualFold(http_listener.Protocol.Value,"https") {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("ssl_profile_name"),
+				"Invalid Http_listener: "+http_listener.Name.Value+", a SSL profile ("+http_listener.Ssl_profile_name.Value+
+				") can only be attached to an HTTPS listener.",
+				"Please, remove the ssl_profile_name or change the protocol then retry.",
+			)
+		}
+	}
+	//hostname and hostnames are mutually exclusive. at least and only one should be set
+	if checkStringConfigured(http_listener.Host_name) && len(http_listener.Host_names) != 0 {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("host_names"),
+			"Invalid Http_listener: "+http_listener.Name.Value+", host_name and host_names are mutually exclusive. Only one should be set.",
+			"Please, remove host_name or host_names then retry.",
+		)
+	}
+	if checkStringMissing(http_listener.Host_name) && len(http_listener.Host_names) == 0 {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("host_name"),
+			"Invalid Http_listener: "+http_listener.Name.Value+", both host_name and host_names are missing. At least and only one should be set.",
+			"Please, add host_name or host_names then retry.",
+		)
+	}
+	if checkStringConfigured(http_listener.Firewall_policy_id) && !checkFirewallPolicyID(http_listener.Firewall_policy_id.Value) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("firewall_policy_id"),
+			"Invalid Http_listener: "+http_listener.Name.Value+", the firewall policy id ("+http_listener.Firewall_policy_id.Value+
+			") is not a valid WAF policy ID. It has to match the format: /subscriptions/{subscription_id}/resourceGroups/{resource_group}/providers/"+
+			"Microsoft.Network/ApplicationGatewayWebApplicationFirewallPolicies/{policy_name}",
+			"Please, change HTTP Listener firewall policy id then retry.",
+		)
+	}
+	//each status code can only be declared once, and the error page has to be a publicly reachable html page
+	status_codes := make(map[string]bool, len(http_listener.Custom_error_configuration))
+	for i := 0; i < len(http_listener.Custom_error_configuration); i++ {
+		customErrorConfiguration := http_listener.Custom_error_configuration[i]
+		customErrorConfiguration_path := path.WithAttributeName("custom_error_configuration").WithElementKeyInt(i)
+		status_code := customErrorConfiguration.Status_code.Value
+		if !customErrorConfiguration.Status_code.Unknown {
+			if status_codes[status_code] {
+				resp.Diagnostics.AddAttributeError(customErrorConfiguration_path.WithAttributeName("status_code"),
+					"Invalid Http_listener: "+http_listener.Name.Value+", the status code ("+status_code+") is declared in more than one custom error configuration.",
+					"Please, change HTTP Listener custom error configuration then retry.",
+				)
+			}
+			status_codes[status_code] = true
+		}
+		if customErrorConfiguration.Custom_error_page_url.Unknown {
+			continue
+		}
+		page_url, err := url.Parse(customErrorConfiguration.Custom_error_page_url.Value)
+		if err != nil || (page_url.Scheme != "http" && page_url.Scheme != "https") || page_url.Host == "" {
+			resp.Diagnostics.AddAttributeError(customErrorConfiguration_path.WithAttributeName("custom_error_page_url"),
+				"Invalid Http_listener: "+http_listener.Name.Value+", the custom error page url ("+customErrorConfiguration.Custom_error_page_url.Value+
+				") for the status code "+status_code+" is not a valid http(s) url.",
+				"Please, change HTTP Listener custom error configuration then retry.",
+			)
+		}else if !strings.HasSuffix(page_url.Path, ".htm") && !strings.HasSuffix(page_url.Path, ".html") {
+			resp.Diagnostics.AddAttributeError(customErrorConfiguration_path.WithAttributeName("custom_error_page_url"),
+				"Invalid Http_listener: "+http_listener.Name.Value+", the custom error page url ("+customErrorConfiguration.Custom_error_page_url.Value+
+				") for the status code "+status_code+" has to be a .htm or .html page.",
+				"Please, change HTTP Listener custom error configuration then retry.",
+			)
+		}
+	}
+}
 func checkHTTPListenerNameInMap(HTTPListenerName string, http_listeners map[string]Http_listener) bool{
 	for _, value := range http_listeners {
 		if HTTPListenerName == value.Name.Value {
@@ -366,4 +460,63 @@ func checkHTTPListenerNameInMap(HTTPListenerName string, http_listeners map[stri
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func checkStringInList(value string, list []string) bool {
+	for i := 0; i < len(list); i++ {
+		if list[i] == value {
+			return true
+		}
+	}
+	return false
+}
+func checkFirewallPolicyID(FirewallPolicyID string) bool {
+	return firewallPolicyIDRegexp.MatchString(FirewallPolicyID)
+}
+func getFirewallPolicyIDState(FirewallPolicyID_state types.String, FirewallPolicyID_prior types.String) types.String {
+	//Azure can return the ID with another case, in this case the ID of the plan (or the prior state) is kept to avoid a diff
+	if !FirewallPolicyID_state.Null && !FirewallPolicyID_prior.Null && !FirewallPolicyID_prior.Unknown &&
+		strings.EqualFold(FirewallPolicyID_state.Value, FirewallPolicyID_prior.Value) {
+		return FirewallPolicyID_prior
+	}
+	return FirewallPolicyID_state
+}
+
+func checkHTTPListenerSslProfile(http_listener Http_listener, plan BindingService, gw ApplicationGateway) (string, bool) {
+	//return the error message when the ssl profile name of the listener is not valid, and true
+	if http_listener.Ssl_profile_name.Value == "" {
+		return "", false
+	}
+	//the ssl profile has to be declared in the binding or to already exist in the gateway
+	if !checkSslProfileNameInMap(http_listener.Ssl_profile_name.Value, plan.Ssl_profiles) &&
+		!checkSslProfileElement(gw, http_listener.Ssl_profile_name.Value) {
+		return "the SSL profile name ("+http_listener.Ssl_profile_name.Value+") doesn't match any SSL profile declared in the binding "+
+			"or existing in the application gateway. ", true
+	}
+	return "", false
+}
+func getHTTPListenerSortedKeys(http_listeners map[string]Http_listener) []string {
+	keys := make([]string, 0, len(http_listeners))
+	for key := range http_listeners {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+func checkFrontendIPConfigurationElement(gw ApplicationGateway, FrontendIPConfigurationName string) bool {
+	exist := false
+	for i := len(gw.Properties.FrontendIPConfigurations) - 1; i >= 0; i-- {
+		if gw.Properties.FrontendIPConfigurations[i].Name == FrontendIPConfigurationName {
+			exist = true
+		}
+	}
+	return exist
+}
+func checkFrontendPortElement(gw ApplicationGateway, FrontendPortName string) bool {
+	exist := false
+	for i := len(gw.Properties.FrontendPorts) - 1; i >= 0; i-- {
+		if gw.Properties.FrontendPorts[i].Name == FrontendPortName {
+			exist = true
+		}
+	}
+	return exist
+}
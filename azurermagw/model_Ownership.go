@@ -0,0 +1,219 @@
+package azurermagw
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The elements of each binding are registered in the tags of the gateway, so a binding can't modify the elements of another one.
+// The registry of a binding is split in tags named "azurermagw-binding.<index>.<binding name>", the value of each tag is a
+// comma-separated list of "<collection>/<element name>" (the names of the gateway elements can't hold a comma nor a slash).
+const ownershipTagPrefix = "azurermagw-binding."
+
+// the max length of the value of an Azure tag
+const maxTagValueLength = 256
+
+// the max length of the name of an Azure tag, and the characters it can't contain
+const maxTagNameLength = 512
+const invalidTagNameCharacters = `<>%&\?/`
+
+// the max number of tags of an Azure resource
+const maxTagCount = 50
+
+// the binding name is part of the tag names, after the prefix and an index of at most 2 digits (the tag count is limited)
+const maxBindingServiceNameLength = maxTagNameLength - len(ownershipTagPrefix) - len("00.")
+
+func getOwnershipKey(collection string, name string) string {
+	return collection + "/" + name
+}
+func getBindingServiceElements(binding BindingService) []string {
+	//return the keys of all the gateway elements managed by the binding
+	elements := []string{
+		getOwnershipKey("backendAddressPools", binding.Backend_address_pool.Name.Value),
+		getOwnershipKey("backendHttpSettingsCollection", binding.Backend_http_settings.Name.Value),
+		getOwnershipKey("probes", binding.Probe.Name.Value),
+		getOwnershipKey("sslCertificates", binding.Ssl_certificate.Name.Value),
+		getOwnershipKey("redirectConfigurations", binding.Redirect_configuration.Name.Value),
+	}
+	for _, httpListener := range binding.Http_listeners {
+		elements = append(elements, getOwnershipKey("httpListeners", httpListener.Name.Value))
+	}
+	for _, requestRoutingRule := range binding.Request_routing_rules {
+		elements = append(elements, getOwnershipKey("requestRoutingRules", requestRoutingRule.Name.Value))
+	}
+	for _, urlPathMap := range binding.Url_path_maps {
+		elements = append(elements, getOwnershipKey("urlPathMaps", urlPathMap.Name.Value))
+	}
+	for _, rewriteRuleSet := range binding.Rewrite_rule_sets {
+		elements = append(elements, getOwnershipKey("rewriteRuleSets", rewriteRuleSet.Name.Value))
+	}
+	for _, sslProfile := range binding.Ssl_profiles {
+		elements = append(elements, getOwnershipKey("sslProfiles", sslProfile.Name.Value))
+	}
+	for _, trustedClientCertificate := range binding.Trusted_client_certificates {
+		elements = append(elements, getOwnershipKey("trustedClientCertificates", trustedClientCertificate.Name.Value))
+	}
+	//the elements removed manually from the gw have no name in the state
+	named_elements := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if !strings.HasSuffix(element, "/") {
+			named_elements = append(named_elements, element)
+		}
+	}
+	sort.Strings(named_elements)
+	return named_elements
+}
+func parseOwnershipTag(tag string) (string, bool) {
+	//return the binding name of a registry tag, and false if the tag is not a registry one
+	if !strings.HasPrefix(tag, ownershipTagPrefix) {
+		return "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(tag, ownershipTagPrefix), ".", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", false
+	}
+	if _, err := strconv.Atoi(parts[0]); err != nil {
+		return "", false
+	}
+	return parts[1], true
+}
+func getElementOwners(gw ApplicationGateway) map[string]string {
+	//return the owner (binding name) of each registered element
+	owners := make(map[string]string)
+	for tag, value := range gw.Tags {
+		bindingServiceName, ok := parseOwnershipTag(tag)
+		if !ok {
+			continue
+		}
+		for _, element := range strings.Split(value, ",") {
+			if element != "" {
+				owners[element] = bindingServiceName
+			}
+		}
+	}
+	return owners
+}
+func getBindingServiceRegisteredElements(gw ApplicationGateway, bindingServiceName string) map[string][]string {
+	//return the sorted names of the elements registered by the binding, by collection (import)
+	elements := make(map[string][]string)
+	for element, owner := range getElementOwners(gw) {
+		parts := strings.SplitN(element, "/", 2)
+		if owner != bindingServiceName || len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		elements[parts[0]] = append(elements[parts[0]], parts[1])
+	}
+	for collection := range elements {
+		sort.Strings(elements[collection])
+	}
+	return elements
+}
+func checkBindingServiceRegistered(gw ApplicationGateway, bindingServiceName string) bool {
+	for tag := range gw.Tags {
+		if name, ok := parseOwnershipTag(tag); ok && name == bindingServiceName {
+			return true
+		}
+	}
+	return false
+}
+func checkBindingServiceOwnership(gw ApplicationGateway, bindingServiceName string, elements []string) ([]string, bool) {
+	//return the elements registered by another binding, and true if there is at least one.
+	//an element that is not registered (created before the registry or outside terraform) can be modified
+	owners := getElementOwners(gw)
+	owned := false
+	var owned_element_list []string
+	for _, element := range elements {
+		if owner, exist := owners[element]; exist && owner != bindingServiceName {
+			owned = true
+			owned_element_list = append(owned_element_list, "\n	- "+element+" (binding: "+owner+")")
+		}
+	}
+	owned_element_list = append(owned_element_list, "\n")
+	return owned_element_list, owned
+}
+func removeBindingServiceOwnership(gw *ApplicationGateway, bindingServiceName string) {
+	for tag := range gw.Tags {
+		if name, ok := parseOwnershipTag(tag); ok && name == bindingServiceName {
+			delete(gw.Tags, tag)
+		}
+	}
+}
+func setBindingServiceOwnership(gw *ApplicationGateway, bindingServiceName string, elements []string) {
+	//replace the registry of the binding. It's sent with the elements in the same PUT, so both are always consistent
+	removeBindingServiceOwnership(gw, bindingServiceName)
+	if gw.Tags == nil {
+		gw.Tags = make(map[string]string)
+	}
+	index := 0
+	value := ""
+	for _, element := range elements {
+		if value != "" && len(value)+1+len(element) > maxTagValueLength {
+			gw.Tags[ownershipTagPrefix+strconv.Itoa(index)+"."+bindingServiceName] = value
+			index++
+			value = ""
+		}
+		if value != "" {
+			value += ","
+		}
+		value += element
+	}
+	if value != "" {
+		gw.Tags[ownershipTagPrefix+strconv.Itoa(index)+"."+bindingServiceName] = value
+	}
+}
+func adoptBindingServiceElements(gw *ApplicationGateway, binding BindingService) []string {
+	//with adopt_existing, the elements of the gw having the name of an element of the binding are replaced by the planned ones.
+	//the adopted elements keep their names, so the elements of the gw referencing them stay valid. Return the adopted elements
+	var adopted []string
+	adopt := func(collection string, name string, exist func(ApplicationGateway, string) bool, remove func(*ApplicationGateway, string)) {
+		if name != "" && exist(*gw, name) {
+			remove(gw, name)
+			adopted = append(adopted, getOwnershipKey(collection, name))
+		}
+	}
+	adopt("backendAddressPools", binding.Backend_address_pool.Name.Value, checkBackendAddressPoolElement, removeBackendAddressPoolElement)
+	adopt("backendHttpSettingsCollection", binding.Backend_http_settings.Name.Value, checkBackendHTTPSettingsElement, removeBackendHTTPSettingsElement)
+	adopt("probes", binding.Probe.Name.Value, checkProbeElement, removeProbeElement)
+	adopt("sslCertificates", binding.Ssl_certificate.Name.Value, checkSslCertificateElement, removeSslCertificateElement)
+	adopt("redirectConfigurations", binding.Redirect_configuration.Name.Value, checkRedirectConfigurationElement, removeRedirectConfigurationElement)
+	for _, httpListener := range binding.Http_listeners {
+		adopt("httpListeners", httpListener.Name.Value, checkHTTPListenerElement, removeHTTPListenerElement)
+	}
+	for _, requestRoutingRule := range binding.Request_routing_rules {
+		adopt("requestRoutingRules", requestRoutingRule.Name.Value, checkRequestRoutingRuleElement, removeRequestRoutingRuleElement)
+	}
+	for _, urlPathMap := range binding.Url_path_maps {
+		adopt("urlPathMaps", urlPathMap.Name.Value, checkURLPathMapElement, removeURLPathMapElement)
+	}
+	for _, rewriteRuleSet := range binding.Rewrite_rule_sets {
+		adopt("rewriteRuleSets", rewriteRuleSet.Name.Value, checkRewriteRuleSetElement, removeRewriteRuleSetElement)
+	}
+	for _, sslProfile := range binding.Ssl_profiles {
+		adopt("sslProfiles", sslProfile.Name.Value, checkSslProfileElement, removeSslProfileElement)
+	}
+	for _, trustedClientCertificate := range binding.Trusted_client_certificates {
+		adopt("trustedClientCertificates", trustedClientCertificate.Name.Value, checkTrustedClientCertificateElement,
+			removeTrustedClientCertificateElement)
+	}
+	sort.Strings(adopted)
+	return adopted
+}
+func getAdoptedElementList(adopted []string, managed []string) ([]string, bool) {
+	//return the adopted elements that were not already managed by the binding, and true if there is at least one
+	managed_set := getReferenceKeySet(managed)
+	found := false
+	var adopted_element_list []string
+	for _, element := range adopted {
+		if !managed_set[strings.ToLower(element)] {
+			found = true
+			adopted_element_list = append(adopted_element_list, "\n	- "+element)
+		}
+	}
+	adopted_element_list = append(adopted_element_list, "\n")
+	return adopted_element_list, found
+}
+func checkGatewayTagCount(gw ApplicationGateway) bool {
+	//Azure rejects the whole update of a resource having too many tags
+	return len(gw.Tags) > maxTagCount
+}
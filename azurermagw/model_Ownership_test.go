@@ -0,0 +1,126 @@
+package azurermagw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOwnershipTag(t *testing.T) {
+	tests := []struct {
+		tag      string
+		name     string
+		registry bool
+	}{
+		{"azurermagw-binding.0.app1", "app1", true},
+		{"azurermagw-binding.12.app1", "app1", true},
+		{"azurermagw-binding.0.app.with.dots", "app.with.dots", true},
+		{"azurermagw-binding.0.", "", false},
+		{"azurermagw-binding.x.app1", "", false},
+		{"azurermagw-binding.app1", "", false},
+		{"azurermagw-binding.", "", false},
+		{"environment", "", false},
+		{"AZURERMAGW-BINDING.0.app1", "", false},
+	}
+	for _, test := range tests {
+		name, registry := parseOwnershipTag(test.tag)
+		if name != test.name || registry != test.registry {
+			t.Errorf("parseOwnershipTag(%q) = (%q, %v), want (%q, %v)", test.tag, name, registry, test.name, test.registry)
+		}
+	}
+}
+
+func TestSetBindingServiceOwnershipChunking(t *testing.T) {
+	//elements of n characters, "probes/" included
+	element := func(n int, suffix string) string {
+		return "probes/" + strings.Repeat("p", n-len("probes/")-len(suffix)) + suffix
+	}
+	tests := []struct {
+		name     string
+		elements []string
+		tags     int
+	}{
+		{"no element", nil, 0},
+		{"one element", []string{element(20, "a")}, 1},
+		//127 + 1 + 128 = 256: the max length of a value is reached, not exceeded
+		{"exactly the max length", []string{element(127, "a"), element(128, "b")}, 1},
+		//128 + 1 + 128 = 257
+		{"one character over the max length", []string{element(128, "a"), element(128, "b")}, 2},
+		{"many elements", []string{element(100, "a"), element(100, "b"), element(100, "c"), element(100, "d"), element(100, "e")}, 3},
+	}
+	for _, test := range tests {
+		gw := ApplicationGateway{Tags: map[string]string{
+			"environment": "test",
+			"azurermagw-binding.0.other": "probes/other",
+		}}
+		setBindingServiceOwnership(&gw, "app1", test.elements)
+
+		tags := 0
+		for tag, value := range gw.Tags {
+			if name, ok := parseOwnershipTag(tag); ok && name == "app1" {
+				tags++
+				if len(value) > maxTagValueLength {
+					t.Errorf("%s: the value of the tag %s has %d characters, more than %d", test.name, tag, len(value), maxTagValueLength)
+				}
+			}
+		}
+		if tags != test.tags {
+			t.Errorf("%s: %d registry tags, want %d", test.name, tags, test.tags)
+		}
+		owners := getElementOwners(gw)
+		for _, element := range test.elements {
+			if owners[element] != "app1" {
+				t.Errorf("%s: the element %s is registered by %q, want app1", test.name, element, owners[element])
+			}
+		}
+		if gw.Tags["environment"] != "test" || owners["probes/other"] != "other" {
+			t.Errorf("%s: the other tags of the gateway were modified: %v", test.name, gw.Tags)
+		}
+
+		//the registry is replaced, so a smaller one doesn't keep the tags of the previous one
+		setBindingServiceOwnership(&gw, "app1", []string{element(20, "z")})
+		if !checkBindingServiceRegistered(gw, "app1") || len(gw.Tags) != 3 {
+			t.Errorf("%s: the registry was not replaced: %v", test.name, gw.Tags)
+		}
+		removeBindingServiceOwnership(&gw, "app1")
+		if checkBindingServiceRegistered(gw, "app1") || len(gw.Tags) != 2 {
+			t.Errorf("%s: the registry was not removed: %v", test.name, gw.Tags)
+		}
+	}
+}
+
+func TestGetBindingServiceRegisteredElements(t *testing.T) {
+	gw := ApplicationGateway{Tags: map[string]string{
+		"azurermagw-binding.0.app1":  "backendAddressPools/app1-pool,httpListeners/app1-https",
+		"azurermagw-binding.1.app1":  "httpListeners/app1-http,probes/app1-probe",
+		"azurermagw-binding.0.app10": "httpListeners/app10-https",
+		"azurermagw-binding.x.app1":  "probes/not-a-registry-tag",
+		"environment":                "test",
+	}}
+	elements := getBindingServiceRegisteredElements(gw, "app1")
+	want := map[string][]string{
+		"backendAddressPools": {"app1-pool"},
+		"httpListeners":       {"app1-http", "app1-https"},
+		"probes":              {"app1-probe"},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Errorf("elements = %v, want %v", elements, want)
+	}
+	if elements := getBindingServiceRegisteredElements(gw, "app2"); len(elements) != 0 {
+		t.Errorf("elements of an unregistered binding = %v, want none", elements)
+	}
+}
+
+func TestCheckGatewayTagCount(t *testing.T) {
+	gw := ApplicationGateway{Tags: map[string]string{}}
+	for i := 0; i < maxTagCount; i++ {
+		gw.Tags[strings.Repeat("t", i+1)] = "value"
+	}
+	if checkGatewayTagCount(gw) {
+		t.Errorf("%d tags are refused, want accepted", len(gw.Tags))
+	}
+	gw.Tags["one more"] = "value"
+	if !checkGatewayTagCount(gw) {
+		t.Errorf("%d tags are accepted, want refused", len(gw.Tags))
+	}
+}
@@ -3,9 +3,13 @@ package azurermagw
 import (
 	//"fmt"
 	//"fmt"
-	//"strings"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 type Probe_json struct {
@@ -26,6 +30,7 @@ type Probe_json struct {
 			StatusCodes []string `json:"statusCodes,omitempty"`
 		} `json:"match"`
 		Host                                string `json:"host,omitempty"`
+		Port                                int    `json:"port,omitempty"`
 		BackendHTTPSettings *[]struct {ID string `json:"id,omitempty"`} `json:"backendHttpSettings"`
 	} `json:"properties"`
 	Type string `json:"type,omitempty"`
@@ -41,9 +46,10 @@ type Probe_tf struct {
 	Unhealthy_threshold							types.Int64		`tfsdk:"unhealthy_threshold"`
 	Pick_host_name_from_backend_http_settings 	types.Bool		`tfsdk:"pick_host_name_from_backend_http_settings"`	
 	Minimum_servers								types.Int64		`tfsdk:"minimum_servers"`
-	//i choose to make Match param as required to avoid Value Conversion Error in terraform when 
-	//this param is not provided
-	Match										Match			`tfsdk:"match"`
+	Host										types.String	`tfsdk:"host"`
+	Port										types.Int64		`tfsdk:"port"`
+	//the match is optional, it stays null when Azure uses its default status codes (200-399)
+	Match										*Match			`tfsdk:"match"`
 	//the BackendHTTPSettings is added automatically when we provide probe name in BackendHTTPSettings params
 }
 type Match	struct{
@@ -70,6 +76,7 @@ func createProbe(probe_plan Probe_tf,AZURE_SUBSCRIPTION_ID string, rg_name strin
 				StatusCodes []string "json:\"statusCodes,omitempty\""
 			} "json:\"match\""; 
 			Host string "json:\"host,omitempty\""; 
+			Port int "json:\"port,omitempty\""; 
 			BackendHTTPSettings *[]struct{
 				ID string "json:\"id,omitempty\""
 			} "json:\"backendHttpSettings\""
@@ -81,13 +88,14 @@ func createProbe(probe_plan Probe_tf,AZURE_SUBSCRIPTION_ID string, rg_name strin
 			UnhealthyThreshold: int(probe_plan.Unhealthy_threshold.Value),
 			PickHostNameFromBackendHTTPSettings: bool(probe_plan.Pick_host_name_from_backend_http_settings.Value),
 			MinServers: int(probe_plan.Minimum_servers.Value),
+			Host: probe_plan.Host.Value,
+			Port: int(probe_plan.Port.Value),
 		},
 		Type: "Microsoft.Network/applicationGateways/probes",
 	}
 
-	//it remains Match struct. we have to check if it is provided or not (actually, i don't have time)
-	
-	if &probe_plan.Match != nil {
+	//the match is optional, when it is not provided Azure uses the status codes 200-399
+	if probe_plan.Match != nil {
 		probe_json.Properties.Match = &struct{
 			Body string "json:\"body,omitempty\""; 
 			StatusCodes []string "json:\"statusCodes,omitempty\""
@@ -122,24 +130,43 @@ func generateProbeState(gw ApplicationGateway, ProbeName string) Probe_tf {
 		Unhealthy_threshold							: types.Int64	{Value: int64(probe_json.Properties.UnhealthyThreshold)},
 		Pick_host_name_from_backend_http_settings 	: types.Bool {Value: bool(probe_json.Properties.PickHostNameFromBackendHTTPSettings)},
 		Minimum_servers								: types.Int64	{Value: int64(probe_json.Properties.MinServers)},
-		Match	: Match {
-					Body		: types.String{Value: probe_json.Properties.Match.Body},
-					Status_code	: []types.String{},
-				},
-	}
-
-	if len(probe_json.Properties.Match.StatusCodes) != 0 {
-		probe_state.Match.Status_code = make([]types.String,len(probe_json.Properties.Match.StatusCodes) )
-	} else {
-		probe_state.Match.Status_code = nil
 	}
-	for i := 0; i < len(probe_json.Properties.Match.StatusCodes); i++ {
-		probe_state.Match.Status_code[i]=types.String{Value: probe_json.Properties.Match.StatusCodes[i]}
+	if probe_json.Properties.Match != nil {
+		probe_state.Match = &Match {
+			Body		: types.String{Value: probe_json.Properties.Match.Body},
+			Status_code	: []types.String{},
+		}
+		if len(probe_json.Properties.Match.StatusCodes) != 0 {
+			probe_state.Match.Status_code = make([]types.String,len(probe_json.Properties.Match.StatusCodes) )
+		} else {
+			probe_state.Match.Status_code = nil
+		}
+		for i := 0; i < len(probe_json.Properties.Match.StatusCodes); i++ {
+			probe_state.Match.Status_code[i]=types.String{Value: probe_json.Properties.Match.StatusCodes[i]}
+		}
 	}
 	//verify if optional parameters are provided, otherwise, they have to set to null
+	if probe_json.Properties.Host != "" {
+		probe_state.Host = types.String{Value: probe_json.Properties.Host}
+	}else{
+		probe_state.Host = types.String{Null: true}
+	}
+	if probe_json.Properties.Port != 0 {
+		probe_state.Port = types.Int64{Value: int64(probe_json.Properties.Port)}
+	}else{
+		probe_state.Port = types.Int64{Null: true}
+	}
 		
 	return probe_state
 }
+func getProbeMatchState(match_state *Match, match_prior *Match) *Match {
+	//Azure returns its default match (200-399 without body) when it is not configured, in this case the block stays null to avoid a diff
+	if match_prior == nil && match_state != nil && match_state.Body.Value == "" &&
+		(len(match_state.Status_code) == 0 || (len(match_state.Status_code) == 1 && match_state.Status_code[0].Value == defaultProbeStatusCodes)) {
+		return nil
+	}
+	return match_state
+}
 func getProbeElementKey(gw ApplicationGateway, ProbeName string) int {
 	key := -1
 	for i := len(gw.Properties.Probes) - 1; i >= 0; i-- {
@@ -164,4 +191,92 @@ func removeProbeElement(gw *ApplicationGateway, ProbeName string) {
 			gw.Properties.Probes = append(gw.Properties.Probes[:i], gw.Properties.Probes[i+1:]...)
 		}
 	}
+}
+// the status codes accepted by Azure when the probe has no match
+const defaultProbeStatusCodes = "200-399"
+
+// the bounds of the interval and the timeout (in seconds) and of the unhealthy threshold accepted by Azure
+const (
+	minProbeInterval           = 1
+	maxProbeInterval           = 86400
+	minProbeTimeout            = 1
+	maxProbeTimeout            = 86400
+	minProbeUnhealthyThreshold = 1
+	maxProbeUnhealthyThreshold = 20
+	minProbePort               = 1
+	maxProbePort               = 65535
+)
+
+// a status code (200) or a range of status codes (200-399)
+var probeStatusCodeRegexp = regexp.MustCompile(`^(\d{3})(-(\d{3}))?$`)
+
+func checkProbeConfig(probe_plan Probe_tf, gw ApplicationGateway) (string, bool) {
+	//return the error message when the probe doesn't satisfy the constraints, and true
+	if !probe_plan.Port.Null && !probe_plan.Port.Unknown {
+		if !checkApplicationGatewayV2(gw) {
+			return "In Probe "+probe_plan.Name.Value+", the port can't be set because the tier of the gateway ("+
+				gw.Properties.Sku.Tier+") is not a v2 one (Standard_v2 or WAF_v2). ", true
+		}
+	}
+	return "", false
+}
+func validateProbeConfig(probe Probe_tf, resp *tfsdk.ValidateResourceConfigResponse) {
+	//check the constraints between the attributes of the probe
+	path := tftypes.NewAttributePath().WithAttributeName("probe")
+	if checkStringConfigured(probe.Host) && probe.Pick_host_name_from_backend_http_settings.Value {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("host"),
+			"Invalid Probe: "+probe.Name.Value+", host and pick_host_name_from_backend_http_settings are mutually exclusive. Only one should be set.",
+			"Please, remove host or disable pick_host_name_from_backend_http_settings then retry.",
+		)
+	}
+	if probe.Match == nil {
+		return
+	}
+	if len(probe.Match.Status_code) == 0 {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("match").WithAttributeName("status_code"),
+			"Invalid Probe: "+probe.Name.Value+", at least one status code has to be set in the match block.",
+			"Please, add a status code then retry.",
+		)
+	}
+	for i, status_code := range probe.Match.Status_code {
+		if !status_code.Unknown && !checkProbeStatusCode(status_code.Value) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("match").WithAttributeName("status_code").WithElementKeyInt(i),
+				"Invalid Probe: "+probe.Name.Value+", the status code ("+status_code.Value+") is not valid. "+
+				"It has to be a single code (200) or a range of codes (200-399) between 100 and 599.",
+				"Please, change the status code then retry.",
+			)
+		}
+	}
+}
+func checkProbeStatusCode(status_code string) bool {
+	matches := probeStatusCodeRegexp.FindStringSubmatch(strings.TrimSpace(status_code))
+	if matches == nil {
+		return false
+	}
+	first, _ := strconv.Atoi(matches[1])
+	last := first
+	if matches[3] != "" {
+		last, _ = strconv.Atoi(matches[3])
+	}
+	return first >= 100 && last <= 599 && first <= last
+}
+func checkProbeCreate(plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
+	if err, fail := checkProbeConfig(plan.Probe, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. "+err,
+			"Please, change probe configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
+func checkProbeUpdate(plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
+	if err, fail := checkProbeConfig(plan.Probe, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. "+err,
+			"Please, change probe configuration then retry.",
+		)
+		return true
+	}
+	return false
 }
\ No newline at end of file
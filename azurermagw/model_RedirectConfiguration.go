@@ -5,6 +5,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 type RedirectConfiguration struct {
@@ -42,6 +43,8 @@ type Redirect_configuration struct {
 	
 }
 
+var redirectTypes = []string{"Permanent", "Temporary", "Found", "SeeOther"}
+
 func createRedirectConfiguration(redirectConfiguration_plan Redirect_configuration, AZURE_SUBSCRIPTION_ID string, rg_name string, agw_name string) (RedirectConfiguration){	
 	redirectConfiguration_json := RedirectConfiguration{
 		Name:       redirectConfiguration_plan.Name.Value,
@@ -146,24 +149,6 @@ func removeRedirectConfigurationElement(gw *ApplicationGateway, RedirectConfigur
 	}
 }
 func checkRedirectConfigurationCreate(plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
-	//fatal-both-exist
-	if plan.Redirect_configuration.Target_listener_name.Value != "" &&
-		plan.Redirect_configuration.Target_url.Value != "" {
-		resp.Diagnostics.AddError(
-		"Unable to create binding. In the Redirect Configuration ("+plan.Redirect_configuration.Name.Value+"), 2 optional parameters mutually exclusive "+ 
-		"are declared: Target_listener_name and Target_url. Only one has to be set. ",
-		"Please, change configuration then retry.",)
-		return true
-	}
-	//fatal both don't exist
-	if plan.Redirect_configuration.Target_listener_name.Value == "" &&
-	plan.Redirect_configuration.Target_url.Value == "" {
-		resp.Diagnostics.AddError(
-		"Unable to create binding. In the Redirect Configuration  ("+plan.Redirect_configuration.Name.Value+"), both optional parameters mutually exclusive "+ 
-		"are missing: Target_listener_name and Target_url. At least and only one has to be set. ",
-		"Please, change configuration then retry.",)
-		return true
-	}	
 	// check if the given Target_listener_name exist in http_listeners map or in the gw
 	if plan.Redirect_configuration.Target_listener_name.Value != "" &&
 		!checkHTTPListenerNameInMap(plan.Redirect_configuration.Target_listener_name.Value, plan.Http_listeners) &&
@@ -178,24 +163,6 @@ func checkRedirectConfigurationCreate(plan BindingService, gw ApplicationGateway
 	return false
 }
 func checkRedirectConfigurationUpdate(plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
-	//fatal-both-exist
-	if plan.Redirect_configuration.Target_listener_name.Value != "" &&
-		plan.Redirect_configuration.Target_url.Value != "" {
-		resp.Diagnostics.AddError(
-		"Unable to update binding. In the Redirect Configuration ("+plan.Redirect_configuration.Name.Value+"), 2 optional parameters mutually exclusive "+ 
-		"are declared: Target_listener_name and Target_url. Only one has to be set. ",
-		"Please, change configuration then retry.",)
-		return true
-	}
-	//fatal both don't exist
-	if plan.Redirect_configuration.Target_listener_name.Value == "" &&
-	plan.Redirect_configuration.Target_url.Value == "" {
-		resp.Diagnostics.AddError(
-		"Unable to update binding. In the Redirect Configuration  ("+plan.Redirect_configuration.Name.Value+"), both optional parameters mutually exclusive "+ 
-		"are missing: Target_listener_name and Target_url. At least and only one has to be set. ",
-		"Please, change configuration then retry.",)
-		return true
-	}
 	// check if the given Target_listener_name exist in http_listeners map or in the gw
 	if plan.Redirect_configuration.Target_listener_name.Value != "" &&
 		!checkHTTPListenerNameInMap(plan.Redirect_configuration.Target_listener_name.Value, plan.Http_listeners) &&
@@ -208,4 +175,22 @@ func checkRedirectConfigurationUpdate(plan BindingService, gw ApplicationGateway
 		return true
 	} 
 	return false
-}
\ No newline at end of file
+}
+func validateRedirectConfigurationConfig(redirectConfiguration Redirect_configuration, resp *tfsdk.ValidateResourceConfigResponse) {
+	//target_listener_name and target_url are mutually exclusive. at least and only one has to be set
+	path := tftypes.NewAttributePath().WithAttributeName("redirect_configuration")
+	if checkStringConfigured(redirectConfiguration.Target_listener_name) && checkStringConfigured(redirectConfiguration.Target_url) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("target_url"),
+			"Invalid Redirect_configuration: "+redirectConfiguration.Name.Value+", target_listener_name and target_url are mutually exclusive. "+
+			"Only one has to be set.",
+			"Please, remove target_listener_name or target_url then retry.",
+		)
+	}
+	if checkStringMissing(redirectConfiguration.Target_listener_name) && checkStringMissing(redirectConfiguration.Target_url) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("target_listener_name"),
+			"Invalid Redirect_configuration: "+redirectConfiguration.Name.Value+", both target_listener_name and target_url are missing. "+
+			"At least and only one has to be set.",
+			"Please, add target_listener_name or target_url then retry.",
+		)
+	}
+}
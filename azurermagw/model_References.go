@@ -0,0 +1,268 @@
+package azurermagw
+
+import (
+	"strings"
+)
+
+// A reference from an element of the gateway to another element of the gateway, by its ID
+type gatewayReference struct {
+	//the key (collection/name) of the referencing element and the attribute holding the reference
+	element		string
+	attribute	string
+	target		string
+	//remove the reference from the referencing element, nil when the reference is required
+	detach		func()
+}
+
+func getReferenceKey(ID string) string {
+	//return the key (collection/name) of the element of an ID:
+	// /subscriptions/<id>/resourceGroups/<rg>/providers/Microsoft.Network/applicationGateways/<gw>/<collection>/<name>
+	parts := strings.Split(strings.TrimRight(ID, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return getOwnershipKey(parts[len(parts)-2], parts[len(parts)-1])
+}
+func getReferenceKeySet(keys []string) map[string]bool {
+	//the names of the gateway elements are case insensitive
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = true
+	}
+	return set
+}
+func removeReferenceID(references *[]struct{ ID string `json:"id,omitempty"` }, ID string) {
+	for i := len(*references) - 1; i >= 0; i-- {
+		if (*references)[i].ID == ID {
+			*references = append((*references)[:i], (*references)[i+1:]...)
+		}
+	}
+}
+func getGatewayReferences(gw *ApplicationGateway) []gatewayReference {
+	//return all the references between the elements of the gw. The detach functions modify the gw in place,
+	//so they have to be called before any other modification of its collections
+	var references []gatewayReference
+	add := func(element string, attribute string, target string, detach func()) {
+		if target != "" {
+			references = append(references, gatewayReference{element: element, attribute: attribute, target: target, detach: detach})
+		}
+	}
+	for i := range gw.Properties.BackendHTTPSettingsCollection {
+		backend := &gw.Properties.BackendHTTPSettingsCollection[i]
+		element := getOwnershipKey("backendHttpSettingsCollection", backend.Name)
+		if backend.Properties.Probe != nil {
+			add(element, "probe", backend.Properties.Probe.ID, func() { backend.Properties.Probe = nil })
+		}
+		if backend.Properties.TrustedRootCertificates != nil {
+			for _, certificate := range *backend.Properties.TrustedRootCertificates {
+				ID := certificate.ID
+				add(element, "trusted root certificate", ID, func() { removeReferenceID(backend.Properties.TrustedRootCertificates, ID) })
+			}
+		}
+		if backend.Properties.AuthenticationCertificates != nil {
+			for _, certificate := range *backend.Properties.AuthenticationCertificates {
+				add(element, "authentication certificate", certificate.ID, nil)
+			}
+		}
+	}
+	for i := range gw.Properties.HTTPListeners {
+		listener := &gw.Properties.HTTPListeners[i]
+		element := getOwnershipKey("httpListeners", listener.Name)
+		if listener.Properties.FrontendIPConfiguration != nil {
+			add(element, "frontend IP configuration", listener.Properties.FrontendIPConfiguration.ID, nil)
+		}
+		if listener.Properties.FrontendPort != nil {
+			add(element, "frontend port", listener.Properties.FrontendPort.ID, nil)
+		}
+		if listener.Properties.SslCertificate != nil {
+			add(element, "SSL certificate", listener.Properties.SslCertificate.ID, nil)
+		}
+		if listener.Properties.SslProfile != nil {
+			add(element, "SSL profile", listener.Properties.SslProfile.ID, func() { listener.Properties.SslProfile = nil })
+		}
+	}
+	for i := range gw.Properties.RequestRoutingRules {
+		rule := &gw.Properties.RequestRoutingRules[i]
+		element := getOwnershipKey("requestRoutingRules", rule.Name)
+		if rule.Properties.HTTPListener != nil {
+			add(element, "http listener", rule.Properties.HTTPListener.ID, nil)
+		}
+		if rule.Properties.BackendAddressPool != nil {
+			add(element, "backend address pool", rule.Properties.BackendAddressPool.ID, nil)
+		}
+		if rule.Properties.BackendHTTPSettings != nil {
+			add(element, "backend http settings", rule.Properties.BackendHTTPSettings.ID, nil)
+		}
+		if rule.Properties.RedirectConfiguration != nil {
+			add(element, "redirect configuration", rule.Properties.RedirectConfiguration.ID, nil)
+		}
+		if rule.Properties.URLPathMap != nil {
+			add(element, "url path map", rule.Properties.URLPathMap.ID, nil)
+		}
+		if rule.Properties.RewriteRuleSet != nil {
+			add(element, "rewrite rule set", rule.Properties.RewriteRuleSet.ID, func() { rule.Properties.RewriteRuleSet = nil })
+		}
+	}
+	for i := range gw.Properties.RedirectConfigurations {
+		redirect := &gw.Properties.RedirectConfigurations[i]
+		if redirect.Properties.TargetListener != nil {
+			add(getOwnershipKey("redirectConfigurations", redirect.Name), "target listener", redirect.Properties.TargetListener.ID, nil)
+		}
+	}
+	for i := range gw.Properties.URLPathMaps {
+		urlPathMap := &gw.Properties.URLPathMaps[i]
+		element := getOwnershipKey("urlPathMaps", urlPathMap.Name)
+		if urlPathMap.Properties.DefaultBackendAddressPool != nil {
+			add(element, "default backend address pool", urlPathMap.Properties.DefaultBackendAddressPool.ID, nil)
+		}
+		if urlPathMap.Properties.DefaultBackendHTTPSettings != nil {
+			add(element, "default backend http settings", urlPathMap.Properties.DefaultBackendHTTPSettings.ID, nil)
+		}
+		if urlPathMap.Properties.DefaultRedirectConfiguration != nil {
+			add(element, "default redirect configuration", urlPathMap.Properties.DefaultRedirectConfiguration.ID, nil)
+		}
+		if urlPathMap.Properties.DefaultRewriteRuleSet != nil {
+			add(element, "default rewrite rule set", urlPathMap.Properties.DefaultRewriteRuleSet.ID,
+				func() { urlPathMap.Properties.DefaultRewriteRuleSet = nil })
+		}
+		for j := range urlPathMap.Properties.PathRules {
+			pathRule := &urlPathMap.Properties.PathRules[j]
+			prefix := "path rule "+pathRule.Name+": "
+			if pathRule.Properties.BackendAddressPool != nil {
+				add(element, prefix+"backend address pool", pathRule.Properties.BackendAddressPool.ID, nil)
+			}
+			if pathRule.Properties.BackendHTTPSettings != nil {
+				add(element, prefix+"backend http settings", pathRule.Properties.BackendHTTPSettings.ID, nil)
+			}
+			if pathRule.Properties.RedirectConfiguration != nil {
+				add(element, prefix+"redirect configuration", pathRule.Properties.RedirectConfiguration.ID, nil)
+			}
+			if pathRule.Properties.RewriteRuleSet != nil {
+				add(element, prefix+"rewrite rule set", pathRule.Properties.RewriteRuleSet.ID, func() { pathRule.Properties.RewriteRuleSet = nil })
+			}
+		}
+	}
+	for i := range gw.Properties.SslProfiles {
+		sslProfile := &gw.Properties.SslProfiles[i]
+		for _, certificate := range sslProfile.Properties.TrustedClientCertificates {
+			ID := certificate.ID
+			add(getOwnershipKey("sslProfiles", sslProfile.Name), "trusted client certificate", ID,
+				func() { removeReferenceID(&sslProfile.Properties.TrustedClientCertificates, ID) })
+		}
+	}
+	return references
+}
+func getRemovedBindingServiceElements(state BindingService, plan BindingService) []string {
+	//return the elements of the state that are not in the plan anymore (removed or renamed)
+	planned := getReferenceKeySet(getBindingServiceElements(plan))
+	var removed []string
+	for _, element := range getBindingServiceElements(state) {
+		if !planned[strings.ToLower(element)] {
+			removed = append(removed, element)
+		}
+	}
+	return removed
+}
+func getDanglingReferences(gw *ApplicationGateway, removed []string, managed []string) []gatewayReference {
+	//return the references to the removed elements held by the elements of the gw that are not managed by the binding.
+	//the elements of the binding are generated again from the plan, so their references are not taken into account
+	removed_set := getReferenceKeySet(removed)
+	managed_set := getReferenceKeySet(managed)
+	var dangling []gatewayReference
+	for _, reference := range getGatewayReferences(gw) {
+		if removed_set[strings.ToLower(getReferenceKey(reference.target))] && !managed_set[strings.ToLower(reference.element)] {
+			dangling = append(dangling, reference)
+		}
+	}
+	return dangling
+}
+func detachGatewayReferences(references []gatewayReference) []gatewayReference {
+	//remove the optional references from their element, and return the required ones that can't be removed
+	var required []gatewayReference
+	for _, reference := range references {
+		if reference.detach == nil {
+			required = append(required, reference)
+			continue
+		}
+		reference.detach()
+	}
+	return required
+}
+func getReferenceList(references []gatewayReference) []string {
+	var reference_list []string
+	for _, reference := range references {
+		reference_list = append(reference_list, "\n	- "+reference.element+" ("+reference.attribute+"): "+getReferenceKey(reference.target))
+	}
+	reference_list = append(reference_list, "\n")
+	return reference_list
+}
+func getGatewayElementKeys(gw ApplicationGateway) map[string]bool {
+	//return the keys (collection/name) of all the elements that can be referenced in the gw
+	var keys []string
+	for _, element := range gw.Properties.AuthenticationCertificates {
+		keys = append(keys, getOwnershipKey("authenticationCertificates", element.Name))
+	}
+	for _, element := range gw.Properties.BackendAddressPools {
+		keys = append(keys, getOwnershipKey("backendAddressPools", element.Name))
+	}
+	for _, element := range gw.Properties.BackendHTTPSettingsCollection {
+		keys = append(keys, getOwnershipKey("backendHttpSettingsCollection", element.Name))
+	}
+	for _, element := range gw.Properties.FrontendIPConfigurations {
+		keys = append(keys, getOwnershipKey("frontendIPConfigurations", element.Name))
+	}
+	for _, element := range gw.Properties.FrontendPorts {
+		keys = append(keys, getOwnershipKey("frontendPorts", element.Name))
+	}
+	for _, element := range gw.Properties.HTTPListeners {
+		keys = append(keys, getOwnershipKey("httpListeners", element.Name))
+	}
+	for _, element := range gw.Properties.Probes {
+		keys = append(keys, getOwnershipKey("probes", element.Name))
+	}
+	for _, element := range gw.Properties.RedirectConfigurations {
+		keys = append(keys, getOwnershipKey("redirectConfigurations", element.Name))
+	}
+	for _, element := range gw.Properties.RequestRoutingRules {
+		keys = append(keys, getOwnershipKey("requestRoutingRules", element.Name))
+	}
+	for _, element := range gw.Properties.RewriteRuleSets {
+		keys = append(keys, getOwnershipKey("rewriteRuleSets", element.Name))
+	}
+	for _, element := range gw.Properties.SslCertificates {
+		keys = append(keys, getOwnershipKey("sslCertificates", element.Name))
+	}
+	for _, element := range gw.Properties.SslProfiles {
+		keys = append(keys, getOwnershipKey("sslProfiles", element.Name))
+	}
+	for _, element := range gw.Properties.TrustedClientCertificates {
+		keys = append(keys, getOwnershipKey("trustedClientCertificates", element.Name))
+	}
+	for _, element := range gw.Properties.TrustedRootCertificates {
+		keys = append(keys, getOwnershipKey("trustedRootCertificates", element.Name))
+	}
+	for _, element := range gw.Properties.URLPathMaps {
+		keys = append(keys, getOwnershipKey("urlPathMaps", element.Name))
+	}
+	return getReferenceKeySet(keys)
+}
+func validateGatewayReferences(gw ApplicationGateway) ([]string, bool) {
+	//resolve every reference of the gw against the collections of the gw, before sending it to Azure.
+	//return all the broken references, and true if there is at least one
+	keys := getGatewayElementKeys(gw)
+	var broken []gatewayReference
+	for _, reference := range getGatewayReferences(&gw) {
+		//the referenced element has to belong to the gw itself
+		if gw.ID != "" && !strings.HasPrefix(strings.ToLower(reference.target), strings.ToLower(gw.ID)+"/") {
+			broken = append(broken, reference)
+			continue
+		}
+		if !keys[strings.ToLower(getReferenceKey(reference.target))] {
+			broken = append(broken, reference)
+		}
+	}
+	if len(broken) == 0 {
+		return nil, false
+	}
+	return getReferenceList(broken), true
+}
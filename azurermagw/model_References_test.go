@@ -0,0 +1,132 @@
+package azurermagw
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testGatewayID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/gw"
+
+func getTestGateway(t *testing.T, properties string) ApplicationGateway {
+	//the IDs of the fixture are relative to the gateway: "{gw}/sslCertificates/cert1"
+	var gw ApplicationGateway
+	data := `{"name":"gw","id":"` + testGatewayID + `","properties":` + strings.ReplaceAll(properties, "{gw}", testGatewayID) + `}`
+	if err := json.Unmarshal([]byte(data), &gw); err != nil {
+		t.Fatalf("the fixture can't be decoded: %s", err)
+	}
+	return gw
+}
+
+func TestValidateGatewayReferences(t *testing.T) {
+	elements := `
+		"frontendIPConfigurations": [{"name": "public"}],
+		"frontendPorts": [{"name": "port_443"}],
+		"sslCertificates": [{"name": "cert1"}],
+		"backendAddressPools": [{"name": "pool1"}],
+		"probes": [{"name": "probe1"}],`
+	tests := []struct {
+		name       string
+		properties string
+		broken     []string
+	}{
+		{
+			name: "all the references are resolved",
+			properties: `{` + elements + `
+				"backendHttpSettingsCollection": [{"name": "settings1", "properties": {"probe": {"id": "{gw}/probes/probe1"}}}],
+				"httpListeners": [{"name": "listener1", "properties": {
+					"frontendIPConfiguration": {"id": "{gw}/frontendIPConfigurations/public"},
+					"frontendPort": {"id": "{gw}/frontendPorts/port_443"},
+					"sslCertificate": {"id": "{gw}/sslCertificates/cert1"}}}],
+				"requestRoutingRules": [{"name": "rule1", "properties": {
+					"httpListener": {"id": "{gw}/httpListeners/listener1"},
+					"backendAddressPool": {"id": "{gw}/backendAddressPools/pool1"},
+					"backendHttpSettings": {"id": "{gw}/backendHttpSettingsCollection/settings1"}}}]}`,
+		},
+		{
+			name: "missing target",
+			properties: `{` + elements + `
+				"httpListeners": [{"name": "listener1", "properties": {
+					"frontendPort": {"id": "{gw}/frontendPorts/port_443"},
+					"sslCertificate": {"id": "{gw}/sslCertificates/missing"}}}]}`,
+			broken: []string{"httpListeners/listener1 (SSL certificate): sslCertificates/missing"},
+		},
+		{
+			name: "target in another gateway",
+			properties: `{` + elements + `
+				"backendHttpSettingsCollection": [{"name": "settings1", "properties": {
+					"probe": {"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/other/probes/probe1"}}}]}`,
+			broken: []string{"backendHttpSettingsCollection/settings1 (probe): probes/probe1"},
+		},
+		{
+			name: "target in a gateway whose name starts with the name of the gateway",
+			properties: `{` + elements + `
+				"backendHttpSettingsCollection": [{"name": "settings1", "properties": {
+					"probe": {"id": "{gw}2/probes/probe1"}}}]}`,
+			broken: []string{"backendHttpSettingsCollection/settings1 (probe): probes/probe1"},
+		},
+		{
+			name: "case insensitive names and IDs",
+			properties: `{` + elements + `
+				"backendHttpSettingsCollection": [{"name": "settings1", "properties": {
+					"probe": {"id": "/SUBSCRIPTIONS/sub/resourcegroups/RG/providers/microsoft.network/applicationgateways/GW/probes/PROBE1"}}}],
+				"httpListeners": [{"name": "listener1", "properties": {
+					"frontendPort": {"id": "{gw}/frontendports/Port_443"},
+					"sslCertificate": {"id": "{gw}/sslCertificates/CERT1"}}}]}`,
+		},
+		{
+			name: "several broken references",
+			properties: `{` + elements + `
+				"backendHttpSettingsCollection": [{"name": "settings1", "properties": {
+					"probe": {"id": "{gw}/probes/missing_probe"},
+					"trustedRootCertificates": [{"id": "{gw}/trustedRootCertificates/missing_root"}]}}],
+				"requestRoutingRules": [{"name": "rule1", "properties": {
+					"httpListener": {"id": "{gw}/httpListeners/missing_listener"},
+					"backendAddressPool": {"id": "{gw}/backendAddressPools/pool1"},
+					"backendHttpSettings": {"id": "{gw}/backendHttpSettingsCollection/settings1"}}}],
+				"urlPathMaps": [{"name": "map1", "properties": {
+					"defaultBackendAddressPool": {"id": "{gw}/backendAddressPools/missing_pool"},
+					"pathRules": [{"name": "path1", "properties": {"rewriteRuleSet": {"id": "{gw}/rewriteRuleSets/missing_set"}}}]}}]}`,
+			broken: []string{
+				"backendHttpSettingsCollection/settings1 (probe): probes/missing_probe",
+				"backendHttpSettingsCollection/settings1 (trusted root certificate): trustedRootCertificates/missing_root",
+				"requestRoutingRules/rule1 (http listener): httpListeners/missing_listener",
+				"urlPathMaps/map1 (default backend address pool): backendAddressPools/missing_pool",
+				"urlPathMaps/map1 (path rule path1: rewrite rule set): rewriteRuleSets/missing_set",
+			},
+		},
+	}
+	for _, test := range tests {
+		gw := getTestGateway(t, test.properties)
+		broken_reference_list, broken := validateGatewayReferences(gw)
+		if broken != (len(test.broken) != 0) {
+			t.Errorf("%s: broken = %v, want %v: %v", test.name, broken, len(test.broken) != 0, broken_reference_list)
+			continue
+		}
+		if !broken {
+			continue
+		}
+		//the list holds one line per broken reference, and the final line break
+		if len(broken_reference_list) != len(test.broken)+1 {
+			t.Errorf("%s: %d broken references, want %d: %v", test.name, len(broken_reference_list)-1, len(test.broken), broken_reference_list)
+			continue
+		}
+		for i, reference := range test.broken {
+			if broken_reference_list[i] != "\n	- "+reference {
+				t.Errorf("%s: broken reference %d = %q, want %q", test.name, i, broken_reference_list[i], "\n	- "+reference)
+			}
+		}
+	}
+}
+
+func TestValidateGatewayReferencesWithoutID(t *testing.T) {
+	//the gateway read from Azure always has an ID, without it the references are only resolved by their collection and name
+	gw := getTestGateway(t, `{
+		"probes": [{"name": "probe1"}],
+		"backendHttpSettingsCollection": [{"name": "settings1", "properties": {
+			"probe": {"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/applicationGateways/other/probes/probe1"}}}]}`)
+	gw.ID = ""
+	if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+		t.Errorf("broken references found: %v", broken_reference_list)
+	}
+}
@@ -1,11 +1,13 @@
 package azurermagw
 
 import (
-	"strconv"
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 type RequestRoutingRule struct {
@@ -41,13 +43,26 @@ type RequestRoutingRule struct {
 	Type string `json:"type"`
 }
 
+// the range of priorities accepted by Azure for the request routing rules
+const (
+	minRequestRoutingRulePriority = 1
+	maxRequestRoutingRulePriority = 20000
+)
+var requestRoutingRuleTypes = []string{"Basic", "PathBasedRouting"}
+
+// the default band of priorities allocated by the provider when priority_range is not set
+const (
+	defaultPriorityRangeStart = 1
+	defaultPriorityRangeEnd   = 300
+)
+
 type Request_routing_rule struct {
 	//required
 	Name         						types.String	`tfsdk:"name"`	
 	Id           						types.String	`tfsdk:"id"`
 	Rule_type							types.String	`tfsdk:"rule_type"`					
 	Http_listener_name           		types.String	`tfsdk:"http_listener_name"`
-	Priority 							types.String	`tfsdk:"priority"`
+	Priority 							types.Int64		`tfsdk:"priority"`
 	//Cannot be set if redirect_configuration_name is not set
 	Backend_address_pool_name			types.String	`tfsdk:"backend_address_pool_name"`
 	Backend_http_settings_name			types.String	`tfsdk:"backend_http_settings_name"`								
@@ -87,7 +102,11 @@ func createRequestRoutingRule(requestRoutingRule_plan *Request_routing_rule, pri
 	HTTPListenerID :=ID+"/httpListeners/"+requestRoutingRule_plan.Http_listener_name.Value
 	requestRoutingRule_json.Properties.HTTPListener = &struct{ID string "json:\"id,omitempty\""}{ID: HTTPListenerID,}
 	
-	if requestRoutingRule_plan.Redirect_configuration_name.Value != "" {
+	if requestRoutingRule_plan.Rule_type.Value == "PathBasedRouting" {
+		//the backend address pool, the backend http settings or the redirect configuration are given by the url path map
+		URLPathMapID:= ID+"/urlPathMaps/"+requestRoutingRule_plan.Url_path_map_name.Value
+		requestRoutingRule_json.Properties.URLPathMap = &struct{ID string "json:\"id,omitempty\""}{ID: URLPathMapID,}
+	}else if requestRoutingRule_plan.Redirect_configuration_name.Value != "" {
 		//redirect_configuration_name is set
 		redirectConfigurationID := ID+"/redirectConfigurations/"+requestRoutingRule_plan.Redirect_configuration_name.Value
 		requestRoutingRule_json.Properties.RedirectConfiguration = &struct{ID string "json:\"id,omitempty\""}{ID: redirectConfigurationID,}
@@ -104,11 +123,6 @@ func createRequestRoutingRule(requestRoutingRule_plan *Request_routing_rule, pri
 		rewriteRuleSetID := ID+"/rewriteRuleSets/"+requestRoutingRule_plan.Rewrite_rule_set_name.Value
 		requestRoutingRule_json.Properties.RewriteRuleSet = &struct{ID string "json:\"id,omitempty\""}{ID: rewriteRuleSetID,}
 	}
-	if requestRoutingRule_plan.Url_path_map_name.Value != "" {
-		//url_path_map_name is set
-		URLPathMapID:= ID+"/urlPathMaps/"+requestRoutingRule_plan.Url_path_map_name.Value
-		requestRoutingRule_json.Properties.URLPathMap = &struct{ID string "json:\"id,omitempty\""}{ID: URLPathMapID,}
-	}
 	
 	return requestRoutingRule_json
 }
@@ -124,7 +138,7 @@ func generateRequestRoutingRuleState(gw ApplicationGateway, RequestRoutingRuleNa
 		Id:                          types.String{Value: requestRoutingRule_json.ID},
 		Rule_type:                   types.String{Value: requestRoutingRule_json.Properties.RuleType},
 		Http_listener_name:          types.String{},
-		Priority:                    types.String{Value: strconv.Itoa(requestRoutingRule_json.Properties.Priority)},
+		Priority:                    types.Int64{Value: int64(requestRoutingRule_json.Properties.Priority)},
 		Backend_address_pool_name:   types.String{},
 		Backend_http_settings_name:  types.String{},
 		Redirect_configuration_name: types.String{},
@@ -192,24 +206,6 @@ func removeRequestRoutingRuleElement(gw *ApplicationGateway, RequestRoutingRuleN
 		}
 	}
 }
-func checkRewriteRuleSetElement(gw ApplicationGateway, RewriteRuleSetName string) bool {
-	exist := false
-	for i := len(gw.Properties.RewriteRuleSets) - 1; i >= 0; i-- {
-		if gw.Properties.RewriteRuleSets[i].Name == RewriteRuleSetName {
-			exist = true
-		}
-	}
-	return exist
-}
-func checkURLPathMapElement(gw ApplicationGateway, URLPathMapName string) bool {
-	exist := false
-	for i := len(gw.Properties.URLPathMaps) - 1; i >= 0; i-- {
-		if gw.Properties.URLPathMaps[i].Name == URLPathMapName {
-			exist = true
-		}
-	}
-	return exist
-}
 func checkRequestRoutingRuleCreate(key string, plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
 	requestRoutingRule_plan := plan.Request_routing_rules[key]
 
@@ -223,19 +219,19 @@ func checkRequestRoutingRuleCreate(key string, plan BindingService, gw Applicati
 		)
 		return true
 	}
-	//check mutual exclusivity
-	if requestRoutingRule_plan.Redirect_configuration_name.Value != "" {
-		//check if one or both are provided, then issue exit error
-		if requestRoutingRule_plan.Backend_address_pool_name.Value != "" ||
-		 	requestRoutingRule_plan.Backend_http_settings_name.Value != ""{
-			// mutual exclusivity error betwenn => exit
+	//the targets depend on the rule type, their exclusivity is checked by validateRequestRoutingRuleConfig
+	if requestRoutingRule_plan.Rule_type.Value == "PathBasedRouting" {
+		//check url_path_map_name: it has to be declared in the binding or to exist in the gw
+		if !checkURLPathMapNameInMap(requestRoutingRule_plan.Url_path_map_name.Value, plan.Url_path_maps) &&
+			!checkURLPathMapElement(gw,requestRoutingRule_plan.Url_path_map_name.Value){
 			resp.Diagnostics.AddError(
-				"Unable to create binding. In the Request Routing Rule (HTTPS) ("+requestRoutingRule_plan.Name.Value+") configuration, "+
-				"redirect_configuration_name cannot be set if both backend_address_pool_name or backend_http_settings_name are set ",
-				"Please, change configuration then retry.",
-				)
+				"Unable to create binding. The url_path_map name ("+requestRoutingRule_plan.Url_path_map_name.Value+
+				") declared in Request_routing_rule: "+ requestRoutingRule_plan.Name.Value+" doesn't match any existing (in the gw) nor declared (in the tf) url path map.",
+				"Please, change url_path_map name then retry.",
+			)
 			return true
 		}
+	}else if requestRoutingRule_plan.Redirect_configuration_name.Value != "" {
 		//check redirect_configuration name
 		if requestRoutingRule_plan.Redirect_configuration_name.Value != plan.Redirect_configuration.Name.Value {
 			// redirect_configuration_name don't match with the existing Redirect_configuration.Name => issue exit error
@@ -247,18 +243,6 @@ func checkRequestRoutingRuleCreate(key string, plan BindingService, gw Applicati
 			return true
 		}
 	}else{
-		//check if one or both are missing, then issue exit error
-		if requestRoutingRule_plan.Backend_address_pool_name.Value == "" ||
-			requestRoutingRule_plan.Backend_http_settings_name.Value == "" {
-			// mutual exclusivity error betwenn => exit			
-			resp.Diagnostics.AddError(
-				"Unable to create binding. In the Request Routing Rule (HTTPS) ("+requestRoutingRule_plan.Name.Value+") configuration, "+
-				"a paramameter is missing: [redirect_configuration_name] or [backend_address_pool_name and backend_http_settings_name]",
-				"Please, change configuration then retry.",
-				)
-			return true
-		}
-		//it's ok, check next constraints
 		//check backend_address_pool_name 
 		if requestRoutingRule_plan.Backend_address_pool_name.Value != plan.Backend_address_pool.Name.Value {
 			resp.Diagnostics.AddError(
@@ -278,28 +262,16 @@ func checkRequestRoutingRuleCreate(key string, plan BindingService, gw Applicati
 			return true
 		}
 	}
-	//check rewrite_rule_set_name
+	//check rewrite_rule_set_name: it has to be declared in the binding or to exist in the gw
 	if requestRoutingRule_plan.Rewrite_rule_set_name.Value != ""{
-		if !checkRewriteRuleSetElement(gw,requestRoutingRule_plan.Rewrite_rule_set_name.Value){
+		if err, fail := checkRewriteRuleSetReference(requestRoutingRule_plan.Rewrite_rule_set_name.Value, plan, gw); fail {
 			resp.Diagnostics.AddError(
-				"Unable to create binding. The rewrite_rule_set name ("+requestRoutingRule_plan.Rewrite_rule_set_name.Value+
-				") declared in Request_routing_rule_https: "+ requestRoutingRule_plan.Name.Value+" doesn't exist in the gateway.",
+				"Unable to create binding. In Request_routing_rule: "+ requestRoutingRule_plan.Name.Value+", "+err,
 				"Please, remove or change rewrite_rule_set name then retry.",
 			)
 			return true
 		}
 	}
-	//check url_path_map_name
-	if requestRoutingRule_plan.Url_path_map_name.Value != ""{
-		if !checkURLPathMapElement(gw,requestRoutingRule_plan.Url_path_map_name.Value){
-			resp.Diagnostics.AddError(
-				"Unable to create binding. The url_path_map name ("+requestRoutingRule_plan.Url_path_map_name.Value+
-				") declared in Request_routing_rule_https: "+ requestRoutingRule_plan.Name.Value+" doesn't exist in the gateway.",
-				"Please, remove or change url_path_map name then retry.",
-			)
-			return true
-		}
-	}
 	return false
 }
 func checkRequestRoutingRuleUpdate(key string, plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
@@ -315,19 +287,19 @@ func checkRequestRoutingRuleUpdate(key string, plan BindingService, gw Applicati
 		)
 		return true
 	}
-	//check mutual exclusivity
-	if requestRoutingRule_plan.Redirect_configuration_name.Value != "" {
-		//check if one or both are provided, then issue exit error
-		if requestRoutingRule_plan.Backend_address_pool_name.Value != "" ||
-		 	requestRoutingRule_plan.Backend_http_settings_name.Value != ""{
-			// mutual exclusivity error betwenn => exit
+	//the targets depend on the rule type, their exclusivity is checked by validateRequestRoutingRuleConfig
+	if requestRoutingRule_plan.Rule_type.Value == "PathBasedRouting" {
+		//check url_path_map_name: it has to be declared in the binding or to exist in the gw
+		if !checkURLPathMapNameInMap(requestRoutingRule_plan.Url_path_map_name.Value, plan.Url_path_maps) &&
+			!checkURLPathMapElement(gw,requestRoutingRule_plan.Url_path_map_name.Value){
 			resp.Diagnostics.AddError(
-				"Unable to update binding. In the Request Routing Rule (HTTPS) ("+requestRoutingRule_plan.Name.Value+") configuration, "+
-				"redirect_configuration_name cannot be set if both backend_address_pool_name or backend_http_settings_name are set ",
-				"Please, change configuration then retry.",
-				)
+				"Unable to update binding. The url_path_map name ("+requestRoutingRule_plan.Url_path_map_name.Value+
+				") declared in Request_routing_rule: "+ requestRoutingRule_plan.Name.Value+" doesn't match any existing (in the gw) nor declared (in the tf) url path map.",
+				"Please, change url_path_map name then retry.",
+			)
 			return true
 		}
+	}else if requestRoutingRule_plan.Redirect_configuration_name.Value != "" {
 		//check redirect_configuration name
 		if requestRoutingRule_plan.Redirect_configuration_name.Value != plan.Redirect_configuration.Name.Value {
 			// redirect_configuration_name don't match with the existing Redirect_configuration.Name => issue exit error
@@ -339,18 +311,6 @@ func checkRequestRoutingRuleUpdate(key string, plan BindingService, gw Applicati
 			return true
 		}
 	}else{
-		//check if one or both are missing, then issue exit error
-		if requestRoutingRule_plan.Backend_address_pool_name.Value == "" ||
-			requestRoutingRule_plan.Backend_http_settings_name.Value == "" {
-			// mutual exclusivity error betwenn => exit			
-			resp.Diagnostics.AddError(
-				"Unable to update binding. In the Request Routing Rule (HTTPS) ("+requestRoutingRule_plan.Name.Value+") configuration, "+
-				"a paramameter is missing: [redirect_configuration_name] or [backend_address_pool_name and backend_http_settings_name]",
-				"Please, change configuration then retry.",
-				)
-			return true
-		}
-		//it's ok, check next constraints
 		//check backend_address_pool_name 
 		if requestRoutingRule_plan.Backend_address_pool_name.Value != plan.Backend_address_pool.Name.Value {
 			resp.Diagnostics.AddError(
@@ -370,29 +330,94 @@ func checkRequestRoutingRuleUpdate(key string, plan BindingService, gw Applicati
 			return true
 		}
 	}
-	//check rewrite_rule_set_name
+	//check rewrite_rule_set_name: it has to be declared in the binding or to exist in the gw
 	if requestRoutingRule_plan.Rewrite_rule_set_name.Value != ""{
-		if !checkRewriteRuleSetElement(gw,requestRoutingRule_plan.Rewrite_rule_set_name.Value){
+		if err, fail := checkRewriteRuleSetReference(requestRoutingRule_plan.Rewrite_rule_set_name.Value, plan, gw); fail {
 			resp.Diagnostics.AddError(
-				"Unable to update binding. The rewrite_rule_set name ("+requestRoutingRule_plan.Rewrite_rule_set_name.Value+
-				") declared in Request_routing_rule_https: "+ requestRoutingRule_plan.Name.Value+" doesn't exist in the gateway.",
+				"Unable to update binding. In Request_routing_rule: "+ requestRoutingRule_plan.Name.Value+", "+err,
 				"Please, remove or change rewrite_rule_set name then retry.",
 			)
 			return true
 		}
 	}
-	//check url_path_map_name
-	if requestRoutingRule_plan.Url_path_map_name.Value != ""{
-		if !checkURLPathMapElement(gw,requestRoutingRule_plan.Url_path_map_name.Value){
-			resp.Diagnostics.AddError(
-				"Unable to update binding. The url_path_map name ("+requestRoutingRule_plan.Url_path_map_name.Value+
-				") declared in Request_routing_rule_https: "+ requestRoutingRule_plan.Name.Value+" doesn't exist in the gateway.",
-				"Please, remove or change url_path_map name then retry.",
+	return false
+}
+func validateRequestRoutingRuleConfig(key string, requestRoutingRule Request_routing_rule, resp *tfsdk.ValidateResourceConfigResponse) {
+	//check the targets of the rule: an url path map for a path based rule, otherwise a redirect configuration or both a backend address pool
+	//and a backend http settings
+	path := tftypes.NewAttributePath().WithAttributeName("request_routing_rules").WithElementKeyString(key)
+	if requestRoutingRule.Rule_type.Unknown {
+		return
+	}
+	if requestRoutingRule.Rule_type.Value == "PathBasedRouting" {
+		if checkStringMissing(requestRoutingRule.Url_path_map_name) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("url_path_map_name"),
+				"Invalid Request_routing_rule: "+requestRoutingRule.Name.Value+", url_path_map_name is required when the rule type is PathBasedRouting.",
+				"Please, add url_path_map_name then retry.",
+			)
+		}
+		//a slice keeps the order of the diagnostics stable
+		for _, target := range []struct {
+			attribute	string
+			value		types.String
+		}{
+			{"backend_address_pool_name", requestRoutingRule.Backend_address_pool_name},
+			{"backend_http_settings_name", requestRoutingRule.Backend_http_settings_name},
+			{"redirect_configuration_name", requestRoutingRule.Redirect_configuration_name},
+		} {
+			attribute := target.attribute
+			if checkStringConfigured(target.value) {
+				resp.Diagnostics.AddAttributeError(path.WithAttributeName(attribute),
+					"Invalid Request_routing_rule: "+requestRoutingRule.Name.Value+", "+attribute+" cannot be set when the rule type is PathBasedRouting. "+
+					"The targets have to be set in the url path map.",
+					"Please, remove "+attribute+" then retry.",
+				)
+			}
+		}
+		return
+	}
+	if checkStringConfigured(requestRoutingRule.Url_path_map_name) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("url_path_map_name"),
+			"Invalid Request_routing_rule: "+requestRoutingRule.Name.Value+", url_path_map_name can only be set when the rule type is PathBasedRouting.",
+			"Please, remove url_path_map_name or change the rule type then retry.",
+		)
+	}
+	validateTargetsConfig("Request_routing_rule: "+requestRoutingRule.Name.Value, path, "", requestRoutingRule.Backend_address_pool_name,
+		requestRoutingRule.Backend_http_settings_name, requestRoutingRule.Redirect_configuration_name, resp)
+}
+func validateTargetsConfig(element string, path *tftypes.AttributePath, prefix string, backendAddressPoolName types.String,
+	backendHTTPSettingsName types.String, redirectConfigurationName types.String, resp *tfsdk.ValidateResourceConfigResponse) {
+	//redirect_configuration_name and backend_*_name are mutually exclusive: a redirect configuration or both backend names have to be set.
+	//the prefix is added to the attribute names, for example default_ for the default targets of an url path map
+	if checkStringConfigured(redirectConfigurationName) {
+		if checkStringConfigured(backendAddressPoolName) || checkStringConfigured(backendHTTPSettingsName) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName(prefix+"redirect_configuration_name"),
+				"Invalid "+element+", "+prefix+"redirect_configuration_name cannot be set if "+prefix+"backend_address_pool_name or "+
+				prefix+"backend_http_settings_name is set.",
+				"Please, remove the redirect configuration name or the backend names then retry.",
+			)
+		}
+		return
+	}
+	if !checkStringMissing(redirectConfigurationName) {
+		return
+	}
+	for _, target := range []struct {
+		attribute	string
+		value		types.String
+	}{
+		{prefix+"backend_address_pool_name", backendAddressPoolName},
+		{prefix+"backend_http_settings_name", backendHTTPSettingsName},
+	} {
+		attribute := target.attribute
+		if checkStringMissing(target.value) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName(attribute),
+				"Invalid "+element+", "+attribute+" is missing. Either "+prefix+"redirect_configuration_name or both "+prefix+
+				"backend_address_pool_name and "+prefix+"backend_http_settings_name have to be set.",
+				"Please, change configuration then retry.",
 			)
-			return true
 		}
 	}
-	return false
 }
 func checkRequestRoutingRuleNameInMap(RequestRoutingRuleName string, request_routing_rules map[string]Request_routing_rule) bool {
 	for _, value := range request_routing_rules {
@@ -401,4 +426,82 @@ func checkRequestRoutingRuleNameInMap(RequestRoutingRuleName string, request_rou
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func checkRequestRoutingRulePriority(priority int64) error {
+	//the priority has to be between 1 (highest priority) and 20000 (lowest priority)
+	if priority < minRequestRoutingRulePriority || priority > maxRequestRoutingRulePriority {
+		return fmt.Errorf("the priority (%d) has to be between %d and %d", priority, minRequestRoutingRulePriority, maxRequestRoutingRulePriority)
+	}
+	return nil
+}
+func getRequestRoutingRulePriority(requestRoutingRule Request_routing_rule) (int, bool) {
+	//the priority is optional. If it's not provided (null or unknown in the plan), it has to be generated by the provider
+	if requestRoutingRule.Priority.Null || requestRoutingRule.Priority.Unknown {
+		return 0, false
+	}
+	if checkRequestRoutingRulePriority(requestRoutingRule.Priority.Value) != nil {
+		return 0, false
+	}
+	return int(requestRoutingRule.Priority.Value), true
+}
+func getRequestRoutingRulePriorityHolder(gw ApplicationGateway, priority int, excluded_names map[string]bool) string {
+	//return the name of the request routing rule of the gw that already holds the priority.
+	//the excluded rules are the ones that will be replaced (or removed) by the binding, so their priorities are free
+	for i := 0; i < len(gw.Properties.RequestRoutingRules); i++ {
+		requestRoutingRule_json := gw.Properties.RequestRoutingRules[i]
+		if requestRoutingRule_json.Properties.Priority == priority && !excluded_names[requestRoutingRule_json.Name] {
+			return requestRoutingRule_json.Name
+		}
+	}
+	return ""
+}
+func getRequestRoutingRulePlannedPriorities(request_routing_rules map[string]Request_routing_rule) []int {
+	//the priorities set in the configuration must not be used when generating the missing ones
+	var priorities []int
+	for _, value := range request_routing_rules {
+		if priority, ok := getRequestRoutingRulePriority(value); ok {
+			priorities = append(priorities, priority)
+		}
+	}
+	return priorities
+}
+func checkPriorityInList(priority int, priorities []int) bool {
+	for i := 0; i < len(priorities); i++ {
+		if priorities[i] == priority {
+			return true
+		}
+	}
+	return false
+}
+func getPriorityRange(priority_range []types.Int64) ([2]int, error) {
+	//the priority range is optional. If it's not provided, the default one is used
+	if len(priority_range) == 0 {
+		return [2]int{defaultPriorityRangeStart, defaultPriorityRangeEnd}, nil
+	}
+	if len(priority_range) != 2 {
+		return [2]int{}, fmt.Errorf("it has to contain exactly 2 values (the first and the last priorities of the band), got %d", len(priority_range))
+	}
+	start := int(priority_range[0].Value)
+	end := int(priority_range[1].Value)
+	if start < minRequestRoutingRulePriority || end > maxRequestRoutingRulePriority || start > end {
+		return [2]int{}, fmt.Errorf("[%d, %d] is not a valid band, the values have to be ordered and between %d and %d",
+			start, end, minRequestRoutingRulePriority, maxRequestRoutingRulePriority)
+	}
+	return [2]int{start, end}, nil
+}
+func getRequestRoutingRuleSortedKeys(request_routing_rules map[string]Request_routing_rule) []string {
+	keys := make([]string, 0, len(request_routing_rules))
+	for key := range request_routing_rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+func checkUnknownInt64InList(values []types.Int64) bool {
+	for i := 0; i < len(values); i++ {
+		if values[i].Unknown {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package azurermagw
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func getTestGatewayWithPriorities(t *testing.T, priorities ...int) ApplicationGateway {
+	rules := make([]string, 0, len(priorities))
+	for i, priority := range priorities {
+		rules = append(rules, fmt.Sprintf(`{"name": "rule%d", "properties": {"priority": %d}}`, i, priority))
+	}
+	return getTestGateway(t, `{"requestRoutingRules": [`+strings.Join(rules, ",")+`]}`)
+}
+
+func TestGetPriorityRange(t *testing.T) {
+	tests := []struct {
+		name           string
+		priority_range []types.Int64
+		want           [2]int
+		valid          bool
+	}{
+		{"default band", nil, [2]int{1, 300}, true},
+		{"empty band", []types.Int64{}, [2]int{1, 300}, true},
+		{"band", []types.Int64{{Value: 1000}, {Value: 1999}}, [2]int{1000, 1999}, true},
+		{"single priority", []types.Int64{{Value: 500}, {Value: 500}}, [2]int{500, 500}, true},
+		{"whole range", []types.Int64{{Value: 1}, {Value: 20000}}, [2]int{1, 20000}, true},
+		{"reversed bounds", []types.Int64{{Value: 1999}, {Value: 1000}}, [2]int{}, false},
+		{"one value", []types.Int64{{Value: 1000}}, [2]int{}, false},
+		{"three values", []types.Int64{{Value: 1000}, {Value: 1500}, {Value: 1999}}, [2]int{}, false},
+		{"below the min priority", []types.Int64{{Value: 0}, {Value: 100}}, [2]int{}, false},
+		{"above the max priority", []types.Int64{{Value: 19000}, {Value: 20001}}, [2]int{}, false},
+	}
+	for _, test := range tests {
+		priority_range, err := getPriorityRange(test.priority_range)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error = %v, want valid = %v", test.name, err, test.valid)
+			continue
+		}
+		if priority_range != test.want {
+			t.Errorf("%s: band = %v, want %v", test.name, priority_range, test.want)
+		}
+	}
+}
+
+func TestGeneratePriority(t *testing.T) {
+	tests := []struct {
+		name                string
+		gw_priorities       []int
+		priority_range      [2]int
+		reserved_priorities []int
+		want                int
+		valid               bool
+	}{
+		{"empty gateway, default band", nil, [2]int{1, 300}, nil, 1, true},
+		{"empty gateway", nil, [2]int{1000, 1999}, nil, 1000, true},
+		{"lowest free priority", []int{1000, 1001, 1003}, [2]int{1000, 1999}, nil, 1002, true},
+		{"priorities of the gateway outside of the band", []int{1, 2, 999, 2000}, [2]int{1000, 1999}, nil, 1000, true},
+		{"reserved priorities", []int{1000}, [2]int{1000, 1999}, []int{1001, 1002}, 1003, true},
+		{"last free priority", []int{10, 11, 12}, [2]int{10, 13}, nil, 13, true},
+		{"full band", []int{10, 11}, [2]int{10, 12}, []int{12}, 0, false},
+		{"full single priority band", []int{500}, [2]int{500, 500}, nil, 0, false},
+	}
+	for _, test := range tests {
+		gw := getTestGatewayWithPriorities(t, test.gw_priorities...)
+		priority, err := generatePriority(gw, test.priority_range, test.reserved_priorities)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error = %v, want valid = %v", test.name, err, test.valid)
+			continue
+		}
+		if priority != test.want {
+			t.Errorf("%s: priority = %d, want %d", test.name, priority, test.want)
+		}
+	}
+}
+
+func TestGeneratePriorityIsDeterministic(t *testing.T) {
+	//the same gateway and configuration always give the same priority, whatever the order of the rules
+	gw1 := getTestGatewayWithPriorities(t, 103, 100, 101)
+	gw2 := getTestGatewayWithPriorities(t, 101, 103, 100)
+	for i := 0; i < 10; i++ {
+		priority1, err1 := generatePriority(gw1, [2]int{100, 200}, []int{104, 102})
+		priority2, err2 := generatePriority(gw2, [2]int{100, 200}, []int{102, 104})
+		if err1 != nil || err2 != nil || priority1 != 105 || priority2 != 105 {
+			t.Fatalf("priorities = %d (%v) and %d (%v), want 105", priority1, err1, priority2, err2)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package azurermagw
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type RewriteRuleSet struct {
+	Name       string `json:"name,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Etag       string `json:"etag,omitempty"`
+	Properties struct {
+		ProvisioningState string        `json:"provisioningState,omitempty"`
+		RewriteRules      []RewriteRule `json:"rewriteRules"`
+	} `json:"properties"`
+	Type string `json:"type,omitempty"`
+}
+
+type RewriteRule struct {
+	Name         string                 `json:"name"`
+	RuleSequence int                    `json:"ruleSequence"`
+	Conditions   []RewriteRuleCondition `json:"conditions"`
+	ActionSet    struct {
+		RequestHeaderConfigurations  []HeaderConfiguration `json:"requestHeaderConfigurations"`
+		ResponseHeaderConfigurations []HeaderConfiguration `json:"responseHeaderConfigurations"`
+		URLConfiguration             *struct {
+			ModifiedPath        string `json:"modifiedPath,omitempty"`
+			ModifiedQueryString string `json:"modifiedQueryString,omitempty"`
+			Reroute             bool   `json:"reroute"`
+		} `json:"urlConfiguration,omitempty"`
+	} `json:"actionSet"`
+}
+
+type RewriteRuleCondition struct {
+	Variable   string `json:"variable"`
+	Pattern    string `json:"pattern"`
+	IgnoreCase bool   `json:"ignoreCase"`
+	Negate     bool   `json:"negate"`
+}
+
+type HeaderConfiguration struct {
+	HeaderName  string `json:"headerName"`
+	HeaderValue string `json:"headerValue"`
+}
+
+// the rule sequence accepted by Azure for the rewrite rules
+const (
+	minRewriteRuleSequence = 1
+	maxRewriteRuleSequence = 1000
+)
+
+type Rewrite_rule_set struct {
+	//required
+	Name         						types.String	`tfsdk:"name"`
+	Id           						types.String	`tfsdk:"id"`
+	Rewrite_rules						map[string]Rewrite_rule	`tfsdk:"rewrite_rules"`
+}
+
+type Rewrite_rule struct {
+	//required
+	Name         						types.String	`tfsdk:"name"`
+	Rule_sequence						types.Int64		`tfsdk:"rule_sequence"`
+	//optional
+	Conditions							[]Rewrite_rule_condition	`tfsdk:"conditions"`
+	Request_header_configurations		[]Header_configuration		`tfsdk:"request_header_configurations"`
+	Response_header_configurations		[]Header_configuration		`tfsdk:"response_header_configurations"`
+	Url									*Rewrite_rule_url			`tfsdk:"url"`
+}
+
+type Rewrite_rule_condition struct {
+	//required
+	Variable							types.String	`tfsdk:"variable"`
+	Pattern								types.String	`tfsdk:"pattern"`
+	//optional
+	Ignore_case							types.Bool		`tfsdk:"ignore_case"`	//default to false
+	Negate								types.Bool		`tfsdk:"negate"`		//default to false
+}
+
+type Header_configuration struct {
+	//required
+	Header_name							types.String	`tfsdk:"header_name"`
+	//optional, an empty value removes the header
+	Header_value						types.String	`tfsdk:"header_value"`
+}
+
+type Rewrite_rule_url struct {
+	//optional
+	Path								types.String	`tfsdk:"path"`
+	Query_string						types.String	`tfsdk:"query_string"`
+	Reroute								types.Bool		`tfsdk:"reroute"`	//default to false
+}
+
+func createRewriteRuleSet(rewriteRuleSet_plan Rewrite_rule_set) RewriteRuleSet {
+	rewriteRuleSet_json := RewriteRuleSet{
+		Name: rewriteRuleSet_plan.Name.Value,
+		Type: "Microsoft.Network/applicationGateways/rewriteRuleSets",
+	}
+	//the rewrite rules are added in the order of their keys, so the generated json is always the same
+	rewriteRuleSet_json.Properties.RewriteRules = []RewriteRule{}
+	for _, key := range getRewriteRuleSortedKeys(rewriteRuleSet_plan.Rewrite_rules) {
+		rewriteRule_plan := rewriteRuleSet_plan.Rewrite_rules[key]
+		rewriteRule_json := RewriteRule{
+			Name:         rewriteRule_plan.Name.Value,
+			RuleSequence: int(rewriteRule_plan.Rule_sequence.Value),
+			Conditions:   make([]RewriteRuleCondition, len(rewriteRule_plan.Conditions)),
+		}
+		for i := 0; i < len(rewriteRule_plan.Conditions); i++ {
+			rewriteRule_json.Conditions[i] = RewriteRuleCondition{
+				Variable:   rewriteRule_plan.Conditions[i].Variable.Value,
+				Pattern:    rewriteRule_plan.Conditions[i].Pattern.Value,
+				IgnoreCase: rewriteRule_plan.Conditions[i].Ignore_case.Value,
+				Negate:     rewriteRule_plan.Conditions[i].Negate.Value,
+			}
+		}
+		rewriteRule_json.ActionSet.RequestHeaderConfigurations = createHeaderConfigurations(rewriteRule_plan.Request_header_configurations)
+		rewriteRule_json.ActionSet.ResponseHeaderConfigurations = createHeaderConfigurations(rewriteRule_plan.Response_header_configurations)
+		if rewriteRule_plan.Url != nil {
+			//url is set
+			rewriteRule_json.ActionSet.URLConfiguration = &struct{
+				ModifiedPath string "json:\"modifiedPath,omitempty\""
+				ModifiedQueryString string "json:\"modifiedQueryString,omitempty\""
+				Reroute bool "json:\"reroute\""
+			}{
+				ModifiedPath:        rewriteRule_plan.Url.Path.Value,
+				ModifiedQueryString: rewriteRule_plan.Url.Query_string.Value,
+				Reroute:             rewriteRule_plan.Url.Reroute.Value,
+			}
+		}
+		rewriteRuleSet_json.Properties.RewriteRules = append(rewriteRuleSet_json.Properties.RewriteRules, rewriteRule_json)
+	}
+	return rewriteRuleSet_json
+}
+func createHeaderConfigurations(headerConfigurations_plan []Header_configuration) []HeaderConfiguration {
+	headerConfigurations_json := make([]HeaderConfiguration, len(headerConfigurations_plan))
+	for i := 0; i < len(headerConfigurations_plan); i++ {
+		headerConfigurations_json[i] = HeaderConfiguration{
+			HeaderName:  headerConfigurations_plan[i].Header_name.Value,
+			HeaderValue: headerConfigurations_plan[i].Header_value.Value,
+		}
+	}
+	return headerConfigurations_json
+}
+func generateRewriteRuleSetState(gw ApplicationGateway, RewriteRuleSetName string, rewrite_rules map[string]Rewrite_rule) Rewrite_rule_set {
+	//retrieve json element from gw
+	index := getRewriteRuleSetElementKey_gw(gw, RewriteRuleSetName)
+	rewriteRuleSet_json := gw.Properties.RewriteRuleSets[index]
+
+	// Map response body to resource schema attribute
+	var rewriteRuleSet_state Rewrite_rule_set
+	rewriteRuleSet_state = Rewrite_rule_set{
+		Name:          types.String{Value: rewriteRuleSet_json.Name},
+		Id:            types.String{Value: rewriteRuleSet_json.ID},
+		Rewrite_rules: make(map[string]Rewrite_rule, len(rewriteRuleSet_json.Properties.RewriteRules)),
+	}
+	//the rewrite rules keep the keys given in the plan (or the state).
+	//a rewrite rule that exists in the gw but not in the map (added manually) is added under its name, so the drift is shown
+	for i := 0; i < len(rewriteRuleSet_json.Properties.RewriteRules); i++ {
+		rewriteRule_json := rewriteRuleSet_json.Properties.RewriteRules[i]
+		key := rewriteRule_json.Name
+		for key_map, value := range rewrite_rules {
+			if value.Name.Value == rewriteRule_json.Name {
+				key = key_map
+			}
+		}
+		rewriteRuleSet_state.Rewrite_rules[key] = generateRewriteRuleState(rewriteRule_json)
+	}
+	return rewriteRuleSet_state
+}
+func generateRewriteRuleState(rewriteRule_json RewriteRule) Rewrite_rule {
+	rewriteRule_state := Rewrite_rule{
+		Name:          types.String{Value: rewriteRule_json.Name},
+		Rule_sequence: types.Int64{Value: int64(rewriteRule_json.RuleSequence)},
+	}
+	//the optional lists stay null when they are empty
+	if len(rewriteRule_json.Conditions) != 0 {
+		rewriteRule_state.Conditions = make([]Rewrite_rule_condition, len(rewriteRule_json.Conditions))
+		for i := 0; i < len(rewriteRule_json.Conditions); i++ {
+			rewriteRule_state.Conditions[i] = Rewrite_rule_condition{
+				Variable:    types.String{Value: rewriteRule_json.Conditions[i].Variable},
+				Pattern:     types.String{Value: rewriteRule_json.Conditions[i].Pattern},
+				Ignore_case: types.Bool{Value: rewriteRule_json.Conditions[i].IgnoreCase},
+				Negate:      types.Bool{Value: rewriteRule_json.Conditions[i].Negate},
+			}
+		}
+	}
+	rewriteRule_state.Request_header_configurations = generateHeaderConfigurationsState(rewriteRule_json.ActionSet.RequestHeaderConfigurations)
+	rewriteRule_state.Response_header_configurations = generateHeaderConfigurationsState(rewriteRule_json.ActionSet.ResponseHeaderConfigurations)
+	if rewriteRule_json.ActionSet.URLConfiguration != nil {
+		rewriteRule_state.Url = &Rewrite_rule_url{
+			Path:         types.String{Null: true},
+			Query_string: types.String{Null: true},
+			Reroute:      types.Bool{Value: rewriteRule_json.ActionSet.URLConfiguration.Reroute},
+		}
+		if rewriteRule_json.ActionSet.URLConfiguration.ModifiedPath != "" {
+			rewriteRule_state.Url.Path = types.String{Value: rewriteRule_json.ActionSet.URLConfiguration.ModifiedPath}
+		}
+		if rewriteRule_json.ActionSet.URLConfiguration.ModifiedQueryString != "" {
+			rewriteRule_state.Url.Query_string = types.String{Value: rewriteRule_json.ActionSet.URLConfiguration.ModifiedQueryString}
+		}
+	}
+	return rewriteRule_state
+}
+func generateHeaderConfigurationsState(headerConfigurations_json []HeaderConfiguration) []Header_configuration {
+	if len(headerConfigurations_json) == 0 {
+		return nil
+	}
+	headerConfigurations_state := make([]Header_configuration, len(headerConfigurations_json))
+	for i := 0; i < len(headerConfigurations_json); i++ {
+		headerConfigurations_state[i] = Header_configuration{
+			Header_name:  types.String{Value: headerConfigurations_json[i].HeaderName},
+			Header_value: types.String{Value: headerConfigurations_json[i].HeaderValue},
+		}
+		if headerConfigurations_json[i].HeaderValue == "" {
+			headerConfigurations_state[i].Header_value = types.String{Null: true}
+		}
+	}
+	return headerConfigurations_state
+}
+func getRewriteRuleSetElementKey_gw(gw ApplicationGateway, RewriteRuleSetName string) int {
+	key := -1
+	for i := len(gw.Properties.RewriteRuleSets) - 1; i >= 0; i-- {
+		if gw.Properties.RewriteRuleSets[i].Name == RewriteRuleSetName {
+			key = i
+		}
+	}
+	return key
+}
+func checkRewriteRuleSetElement(gw ApplicationGateway, RewriteRuleSetName string) bool {
+	exist := false
+	for i := len(gw.Properties.RewriteRuleSets) - 1; i >= 0; i-- {
+		if gw.Properties.RewriteRuleSets[i].Name == RewriteRuleSetName {
+			exist = true
+		}
+	}
+	return exist
+}
+func removeRewriteRuleSetElement(gw *ApplicationGateway, RewriteRuleSetName string) {
+	for i := len(gw.Properties.RewriteRuleSets) - 1; i >= 0; i-- {
+		if gw.Properties.RewriteRuleSets[i].Name == RewriteRuleSetName {
+			gw.Properties.RewriteRuleSets = append(gw.Properties.RewriteRuleSets[:i], gw.Properties.RewriteRuleSets[i+1:]...)
+		}
+	}
+}
+func checkRewriteRuleSetNameInMap(RewriteRuleSetName string, rewrite_rule_sets map[string]Rewrite_rule_set) bool {
+	for _, value := range rewrite_rule_sets {
+		if RewriteRuleSetName == value.Name.Value {
+			return true
+		}
+	}
+	return false
+}
+func getRewriteRuleSortedKeys(rewrite_rules map[string]Rewrite_rule) []string {
+	keys := make([]string, 0, len(rewrite_rules))
+	for key := range rewrite_rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+func checkApplicationGatewayV2(gw ApplicationGateway) bool {
+	//the rewrite rule sets are only supported by the v2 SKUs
+	return gw.Properties.Sku.Tier == "Standard_v2" || gw.Properties.Sku.Tier == "WAF_v2"
+}
+func checkRewriteRuleSetReference(RewriteRuleSetName string, plan BindingService, gw ApplicationGateway) (string, bool) {
+	//a rewrite rule set has to be declared in the binding or to exist in the gw, and the gw has to be a v2 one
+	if !checkApplicationGatewayV2(gw) {
+		return "The rewrite rule set ("+RewriteRuleSetName+") can't be used because the tier of the gateway ("+gw.Properties.Sku.Tier+
+			") is not a v2 one (Standard_v2 or WAF_v2). ", true
+	}
+	if !checkRewriteRuleSetNameInMap(RewriteRuleSetName, plan.Rewrite_rule_sets) && !checkRewriteRuleSetElement(gw, RewriteRuleSetName) {
+		return "The rewrite rule set name ("+RewriteRuleSetName+") doesn't match any existing (in the gw) nor declared (in the tf) rewrite rule set. ", true
+	}
+	return "", false
+}
+func checkRewriteRuleSetConfig(rewriteRuleSet_plan Rewrite_rule_set, gw ApplicationGateway) (string, bool) {
+	//return the error message when the rewrite rule set doesn't satisfy the constraints, and true
+	if !checkApplicationGatewayV2(gw) {
+		return "The rewrite rule set ("+rewriteRuleSet_plan.Name.Value+") can't be added because the tier of the gateway ("+gw.Properties.Sku.Tier+
+			") is not a v2 one (Standard_v2 or WAF_v2). ", true
+	}
+	if len(rewriteRuleSet_plan.Rewrite_rules) == 0 {
+		return "The rewrite rule set ("+rewriteRuleSet_plan.Name.Value+") has to contain at least one rewrite rule. ", true
+	}
+	for key, rewriteRule_plan := range rewriteRuleSet_plan.Rewrite_rules {
+		element := "rewrite rule ("+rewriteRule_plan.Name.Value+") of the rewrite rule set ("+rewriteRuleSet_plan.Name.Value+")"
+		for key1, rewriteRule_plan1 := range rewriteRuleSet_plan.Rewrite_rules {
+			if rewriteRule_plan.Name.Value == rewriteRule_plan1.Name.Value && key != key1 {
+				return "The rewrite rules ("+key+" and "+key1+") of the rewrite rule set ("+rewriteRuleSet_plan.Name.Value+") have the same name: "+
+					rewriteRule_plan.Name.Value+". ", true
+			}
+		}
+		for i := 0; i < len(rewriteRule_plan.Conditions); i++ {
+			variable := rewriteRule_plan.Conditions[i].Variable.Value
+			if !strings.HasPrefix(variable, "var_") && !strings.HasPrefix(variable, "http_req_") && !strings.HasPrefix(variable, "http_resp_") {
+				return "The condition variable ("+variable+") of the "+element+" is not valid. It has to be a server variable (var_*), "+
+					"a request header (http_req_*) or a response header (http_resp_*). ", true
+			}
+		}
+		//a rewrite rule without action has no effect
+		if len(rewriteRule_plan.Request_header_configurations) == 0 && len(rewriteRule_plan.Response_header_configurations) == 0 &&
+			rewriteRule_plan.Url == nil {
+			return "The "+element+" has no action. At least one of request_header_configurations, response_header_configurations or url has to be set. ", true
+		}
+		if rewriteRule_plan.Url != nil && rewriteRule_plan.Url.Path.Value == "" && rewriteRule_plan.Url.Query_string.Value == "" {
+			return "In the url of the "+element+", at least one of path or query_string has to be set. ", true
+		}
+	}
+	return "", false
+}
+func checkRewriteRuleSetCreate(rewriteRuleSet_plan Rewrite_rule_set, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
+	if err, fail := checkRewriteRuleSetConfig(rewriteRuleSet_plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. "+err,
+			"Please, change rewrite rule set configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
+func checkRewriteRuleSetUpdate(rewriteRuleSet_plan Rewrite_rule_set, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
+	if err, fail := checkRewriteRuleSetConfig(rewriteRuleSet_plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. "+err,
+			"Please, change rewrite rule set configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
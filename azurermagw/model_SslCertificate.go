@@ -5,10 +5,23 @@ import (
 	//"fmt"
 	//"strings"
 
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
 )
 
 type SslCertificate struct {
@@ -34,7 +47,43 @@ type Ssl_certificate struct {
 	Key_vault_secret_id							types.String	`tfsdk:"key_vault_secret_id"`
 	Data	                       				types.String	`tfsdk:"data"`								
 	Password									types.String	`tfsdk:"password"`
-	//Public_cert_data							types.String	`tfsdk:"public_cert_data"`
+	Expiry_warning_days							types.Int64		`tfsdk:"expiry_warning_days"`
+	Key_vault_secret_version					types.String	`tfsdk:"key_vault_secret_version"`
+	//computed from the public certificate returned by the gateway
+	Public_cert_data							types.String	`tfsdk:"public_cert_data"`
+	Thumbprint									types.String	`tfsdk:"thumbprint"`
+	Subject										types.String	`tfsdk:"subject"`
+	Issuer										types.String	`tfsdk:"issuer"`
+	Not_before									types.String	`tfsdk:"not_before"`
+	Not_after									types.String	`tfsdk:"not_after"`
+	Dns_names									[]types.String	`tfsdk:"dns_names"`
+}
+
+//default number of days before the expiry of the certificate to warn about it during the plan
+const defaultExpiryWarningDays = 30
+
+//Key Vault secret ID: https://<vault>.vault.<cloud suffix>/secrets/<name>[/<version>]
+type keyVaultSecretID struct {
+	Vault   string
+	Name    string
+	Version string
+}
+
+var keyVaultNameRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{1,22}[a-zA-Z0-9]$`)
+var keyVaultSecretNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9-]{1,127}$`)
+var keyVaultSecretVersionRegexp = regexp.MustCompile(`^[a-fA-F0-9]{32}$`)
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
 }
 
 func createSslCertificate(sslCertificate_plan Ssl_certificate,AZURE_SUBSCRIPTION_ID string, rg_name string, agw_name string) (SslCertificate){	
@@ -63,10 +112,9 @@ func createSslCertificate(sslCertificate_plan Ssl_certificate,AZURE_SUBSCRIPTION
 	}
 	if sslCertificate_plan.Data.Value != "" {
 		//only data is provided. check the password	
-		// data must be base64 encoded (from azurerm provider)
-		// output.ApplicationGatewaySslCertificatePropertiesFormat.Data = utils.String(utils.Base64EncodeIfNot(data))
-		//sslCertificate_json.Properties.PublicCertData = base64EncodeIfNot(sslCertificate_plan.Data.Value) only for GET
-		sslCertificate_json.Properties.Data = base64EncodeIfNot(sslCertificate_plan.Data.Value) //only for PUT
+		// data is the base64 encoded PFX, it was already decoded and parsed by parsePFX
+		//sslCertificate_json.Properties.PublicCertData is only for GET
+		sslCertificate_json.Properties.Data = strings.TrimSpace(sslCertificate_plan.Data.Value) //only for PUT
 		sslCertificate_json.Properties.Password = sslCertificate_plan.Password.Value 
 	}
 	return sslCertificate_json
@@ -85,21 +133,217 @@ func generateSslCertificateState(gw ApplicationGateway, SslCertificateName strin
 		//Data:                types.String{},
 		//Password:            types.String{},
 	}
+	sslCertificate_state.Key_vault_secret_version = types.String{Null: true}
 	if sslCertificate_json.Properties.KeyVaultSecretID != "" {
 		sslCertificate_state.Key_vault_secret_id = types.String{Value: sslCertificate_json.Properties.KeyVaultSecretID}
 		sslCertificate_state.Data.Null = true
 		sslCertificate_state.Password.Null = true
+		//a versionless secret ID is rotated by the gateway, the certificate in use is then identified by its thumbprint
+		if secret_id, err := parseKeyVaultSecretID(sslCertificate_json.Properties.KeyVaultSecretID); err == nil && secret_id.Version != "" {
+			sslCertificate_state.Key_vault_secret_version = types.String{Value: secret_id.Version}
+		}
 	}else{
 		sslCertificate_state.Key_vault_secret_id.Null = true
-		//sslCertificate_state.Data = types.String{Value: sslCertificate_json.Properties.PublicCertData}
-		sslCertificate_state.Password = types.String{Value: ""} //sslCertificate_json.Properties.Password
-		//sslCertificate_state.Public_cert_data = types.String{Value: sslCertificate_json.Properties.PublicCertData}
-		//the problem here is that the value of PublicCertData extracted from the gw_response resulted after calling updateGw() 
-		// is null because the API is under executing the request (which take some seconds)
+		//the PFX and its password are never returned by the API, they are restored from the plan or the prior state
+		//by getSslCertificatePFXState when the public certificate of the gateway still matches them
+		sslCertificate_state.Data.Null = true
+		sslCertificate_state.Password.Null = true
 	}
+
+	//the PublicCertData can be empty just after the update of the gateway, the metadata are then available on the next refresh
+	sslCertificate_state.Public_cert_data = types.String{Null: true}
+	sslCertificate_state.Thumbprint = types.String{Null: true}
+	sslCertificate_state.Subject = types.String{Null: true}
+	sslCertificate_state.Issuer = types.String{Null: true}
+	sslCertificate_state.Not_before = types.String{Null: true}
+	sslCertificate_state.Not_after = types.String{Null: true}
+	if sslCertificate_json.Properties.PublicCertData != "" {
+		sslCertificate_state.Public_cert_data = types.String{Value: sslCertificate_json.Properties.PublicCertData}
+		if certificate, err := getPublicCertDataCertificate(sslCertificate_json.Properties.PublicCertData); err == nil {
+			thumbprint := sha1.Sum(certificate.Raw)
+			sslCertificate_state.Thumbprint = types.String{Value: strings.ToUpper(hex.EncodeToString(thumbprint[:]))}
+			sslCertificate_state.Subject = types.String{Value: certificate.Subject.String()}
+			sslCertificate_state.Issuer = types.String{Value: certificate.Issuer.String()}
+			sslCertificate_state.Not_before = types.String{Value: certificate.NotBefore.UTC().Format(time.RFC3339)}
+			sslCertificate_state.Not_after = types.String{Value: certificate.NotAfter.UTC().Format(time.RFC3339)}
+			for _, dns_name := range certificate.DNSNames {
+				sslCertificate_state.Dns_names = append(sslCertificate_state.Dns_names, types.String{Value: dns_name})
+			}
+		}
+	}
+	//the expiry warning is only used by the provider, it doesn't exist in the gateway
+	sslCertificate_state.Expiry_warning_days = types.Int64{Value: defaultExpiryWarningDays}
 	
 	return sslCertificate_state
 }
+func getExpiryWarningDaysState(sslCertificate_state Ssl_certificate, sslCertificate_prior Ssl_certificate) Ssl_certificate {
+	//keep the configured value, the default one is only set when there is no prior value
+	if !sslCertificate_prior.Expiry_warning_days.Null && !sslCertificate_prior.Expiry_warning_days.Unknown {
+		sslCertificate_state.Expiry_warning_days = sslCertificate_prior.Expiry_warning_days
+	}
+	return sslCertificate_state
+}
+func getSslCertificatePFXState(sslCertificate_state Ssl_certificate, sslCertificate_prior Ssl_certificate, gw ApplicationGateway) Ssl_certificate {
+	//nothing to restore for a key vault certificate, or when the prior state has no PFX (import)
+	if !sslCertificate_state.Key_vault_secret_id.Null || sslCertificate_prior.Data.Value == "" {
+		return sslCertificate_state
+	}
+	index := getSslCertificateElementKey(gw, sslCertificate_state.Name.Value)
+	if index < 0 {
+		return sslCertificate_state
+	}
+	//the PublicCertData can be empty just after the update of the gateway, because the API is still processing the certificate.
+	//otherwise, it has to contain the certificate of the PFX. if not, the certificate was changed outside terraform
+	publicCertData := gw.Properties.SslCertificates[index].Properties.PublicCertData
+	if publicCertData == "" || checkPFXPublicCertData(sslCertificate_prior.Data.Value, sslCertificate_prior.Password.Value, publicCertData) {
+		sslCertificate_state.Data = sslCertificate_prior.Data
+		sslCertificate_state.Password = sslCertificate_prior.Password
+	}
+	return sslCertificate_state
+}
+func parseKeyVaultSecretID(id string) (keyVaultSecretID, error) {
+	var secret_id keyVaultSecretID
+	format_error := errors.New("the Key Vault secret ID has to match the format https://<vault>.vault.azure.net/secrets/<name>[/<version>]")
+	parsed_url, err := url.Parse(id)
+	if err != nil || parsed_url.Scheme != "https" || parsed_url.RawQuery != "" || parsed_url.Fragment != "" {
+		return secret_id, format_error
+	}
+	host_parts := strings.SplitN(parsed_url.Hostname(), ".", 3)
+	if len(host_parts) != 3 || host_parts[1] != "vault" || host_parts[2] == "" {
+		return secret_id, format_error
+	}
+	path_parts := strings.Split(strings.Trim(parsed_url.Path, "/"), "/")
+	if len(path_parts) < 2 || len(path_parts) > 3 || path_parts[0] != "secrets" {
+		return secret_id, format_error
+	}
+	secret_id.Vault = host_parts[0]
+	secret_id.Name = path_parts[1]
+	if len(path_parts) == 3 {
+		secret_id.Version = path_parts[2]
+	}
+	if !keyVaultNameRegexp.MatchString(secret_id.Vault) {
+		return secret_id, errors.New("the Key Vault name ("+secret_id.Vault+") is not valid: it has to be 3-24 alphanumeric characters or hyphens, "+
+			"start with a letter and end with a letter or a digit")
+	}
+	if !keyVaultSecretNameRegexp.MatchString(secret_id.Name) {
+		return secret_id, errors.New("the secret name ("+secret_id.Name+") is not valid: it has to be 1-127 alphanumeric characters or hyphens")
+	}
+	if secret_id.Version != "" && !keyVaultSecretVersionRegexp.MatchString(secret_id.Version) {
+		return secret_id, errors.New("the secret version ("+secret_id.Version+") is not valid: it has to be 32 hexadecimal characters")
+	}
+	return secret_id, nil
+}
+func parsePFX(data string, password string) (*x509.Certificate, error) {
+	//return the certificate of the private key contained in the PFX
+	pfx, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, errors.New("the data has to be the base64 encoded content of the PFX file (for example with filebase64())")
+	}
+	blocks, err := pkcs12.ToPEM(pfx, password)
+	if err == pkcs12.ErrIncorrectPassword {
+		return nil, errors.New("the password is incorrect")
+	}
+	if err != nil {
+		//a PFX exported without its private key is refused by the decoder, it has to hold the key and its certificate
+		return nil, errors.New("the PFX can't be decoded, it has to contain a private key and its certificate: "+err.Error())
+	}
+	var key_block *pem.Block
+	var certificate_blocks []*pem.Block
+	for _, block := range blocks {
+		switch block.Type {
+		case "PRIVATE KEY":
+			key_block = block
+		case "CERTIFICATE":
+			certificate_blocks = append(certificate_blocks, block)
+		}
+	}
+	if key_block == nil {
+		return nil, errors.New("the PFX doesn't contain a private key")
+	}
+	if len(certificate_blocks) == 0 {
+		return nil, errors.New("the PFX doesn't contain a certificate")
+	}
+	//the certificate of the private key shares its localKeyId, the other ones are the chain
+	certificate_block := certificate_blocks[0]
+	for _, block := range certificate_blocks {
+		if block.Headers["localKeyId"] != "" && block.Headers["localKeyId"] == key_block.Headers["localKeyId"] {
+			certificate_block = block
+		}
+	}
+	certificate, err := x509.ParseCertificate(certificate_block.Bytes)
+	if err != nil {
+		return nil, errors.New("the certificate of the PFX can't be parsed: "+err.Error())
+	}
+	return certificate, nil
+}
+func parsePublicCertData(publicCertData string) ([]*x509.Certificate, error) {
+	//the PublicCertData is a base64 encoded PKCS#7 structure that embeds the DER encoded certificates
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicCertData))
+	if err != nil {
+		return nil, errors.New("the public certificate data is not base64 encoded")
+	}
+	var content_info pkcs7ContentInfo
+	var signed_data pkcs7SignedData
+	if _, err := asn1.Unmarshal(der, &content_info); err != nil {
+		//not a PKCS#7 structure, so it can only be the DER encoded certificates
+		return x509.ParseCertificates(der)
+	}
+	if _, err := asn1.Unmarshal(content_info.Content.Bytes, &signed_data); err != nil {
+		return nil, errors.New("the public certificate data can't be parsed: "+err.Error())
+	}
+	certificates, err := x509.ParseCertificates(signed_data.Certificates.Bytes)
+	if err != nil {
+		return nil, errors.New("the public certificate data can't be parsed: "+err.Error())
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("the public certificate data doesn't contain any certificate")
+	}
+	return certificates, nil
+}
+func getPublicCertDataCertificate(publicCertData string) (*x509.Certificate, error) {
+	//return the server certificate, the other ones are the CA certificates of the chain
+	certificates, err := parsePublicCertData(publicCertData)
+	if err != nil {
+		return nil, err
+	}
+	for _, certificate := range certificates {
+		if !certificate.IsCA {
+			return certificate, nil
+		}
+	}
+	return certificates[0], nil
+}
+func checkPFXPublicCertData(data string, password string, publicCertData string) bool {
+	certificate, err := parsePFX(data, password)
+	if err != nil {
+		return false
+	}
+	certificates, err := parsePublicCertData(publicCertData)
+	if err != nil {
+		return false
+	}
+	for _, public_certificate := range certificates {
+		if public_certificate.Equal(certificate) {
+			return true
+		}
+	}
+	return false
+}
+func checkSslCertificateExpiry(certificate *x509.Certificate, expiry_warning_days int64) (string, bool) {
+	//return the warning message when the certificate expires within the given number of days, and true
+	if expiry_warning_days <= 0 {
+		return "", false
+	}
+	remaining := time.Until(certificate.NotAfter)
+	if remaining <= 0 {
+		return "the certificate ("+certificate.Subject.String()+") expired on "+certificate.NotAfter.UTC().Format(time.RFC3339), true
+	}
+	if remaining < time.Duration(expiry_warning_days)*24*time.Hour {
+		return "the certificate ("+certificate.Subject.String()+") expires on "+certificate.NotAfter.UTC().Format(time.RFC3339)+
+			", in less than "+strconv.FormatInt(expiry_warning_days, 10)+" days", true
+	}
+	return "", false
+}
 func getSslCertificateElementKey(gw ApplicationGateway, SslCertificateName string) int {
 	key := -1
 	for i := len(gw.Properties.SslCertificates) - 1; i >= 0; i-- {
@@ -125,81 +369,36 @@ func removeSslCertificateElement(gw *ApplicationGateway, SslCertificateName stri
 		}
 	}
 }
-func checkSslCertificateCreate(plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
+func validateSslCertificateConfig(sslCertificate Ssl_certificate, resp *tfsdk.ValidateResourceConfigResponse) {
 	//there is 2 constraints we have to check for SSLCertificate 
 	//   1) Data and Key_vault_secret_id are optional but one of them has to be provided
 	//   2) If Data is provided, Password is required
-	
-	//fatal-both-exist
-	if plan.Ssl_certificate.Key_vault_secret_id.Value != "" && plan.Ssl_certificate.Data.Value != "" {
-		resp.Diagnostics.AddError(
-			"Unable to create binding. In the SSL Certificate ("+plan.Ssl_certificate.Name.Value+") configuration, 2 optional parameters mutually exclusive "+ 
-			"are declared: Data and Key_vault_secret_id. Only one has to be set. ",
-			"Please, change configuration then retry.",				)
-		return true
-	}	
-	//fatal-both-miss
-	if plan.Ssl_certificate.Key_vault_secret_id.Value == "" && plan.Ssl_certificate.Data.Value == "" {
-		resp.Diagnostics.AddError(
-			"Unable to create binding. In the SSL Certificate  ("+plan.Ssl_certificate.Name.Value+") configuration, both optional parameters mutually exclusive "+ 
-			"are missing: Data and Key_vault_secret_id. At least and only one has to be set. ",
-			"Please, change configuration then retry.",
-			)
-		return true
+	//the PFX itself is decoded by ModifyPlan, once the data and the password are known
+	path := tftypes.NewAttributePath().WithAttributeName("ssl_certificate")
+	if checkStringConfigured(sslCertificate.Key_vault_secret_id) && checkStringConfigured(sslCertificate.Data) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("data"),
+			"Invalid SSL Certificate: "+sslCertificate.Name.Value+", data and key_vault_secret_id are mutually exclusive. Only one has to be set.",
+			"Please, remove data or key_vault_secret_id then retry.",
+		)
 	}
-	// miss password
-	if plan.Ssl_certificate.Data.Value != "" && plan.Ssl_certificate.Password.Value == "" {
-		resp.Diagnostics.AddError(
-			"Unable to create binding. In the SSL Certificate  ("+plan.Ssl_certificate.Name.Value+") configuration, Data parameter (pfx file content) "+ 
-			"is provided without password. ",
-			"Please, add password then retry.",
-			)
-		return true
+	if checkStringMissing(sslCertificate.Key_vault_secret_id) && checkStringMissing(sslCertificate.Data) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("key_vault_secret_id"),
+			"Invalid SSL Certificate: "+sslCertificate.Name.Value+", both data and key_vault_secret_id are missing. At least and only one has to be set.",
+			"Please, add data or key_vault_secret_id then retry.",
+		)
 	}
-	return false
-}
-func checkSslCertificateUpdate(plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
-	//there is 2 constraints we have to check for SSLCertificate 
-	//   1) Data and Key_vault_secret_id are optional but one of them has to be provided
-	//   2) If Data is provided, Password is required
-	
-	//fatal-both-exist
-	if plan.Ssl_certificate.Key_vault_secret_id.Value != "" && plan.Ssl_certificate.Data.Value != "" {
-		resp.Diagnostics.AddError(
-			"Unable to update binding. In the SSL Certificate ("+plan.Ssl_certificate.Name.Value+") configuration, 2 optional parameters mutually exclusive "+ 
-			"are declared: Data and Key_vault_secret_id. Only one has to be set. ",
-			"Please, change configuration then retry.",				)
-		return true
-	}	
-	//fatal-both-miss
-	if plan.Ssl_certificate.Key_vault_secret_id.Value == "" && plan.Ssl_certificate.Data.Value == "" {
-		resp.Diagnostics.AddError(
-			"Unable to update binding. In the SSL Certificate  ("+plan.Ssl_certificate.Name.Value+") configuration, both optional parameters mutually exclusive "+ 
-			"are missing: Data and Key_vault_secret_id. At least and only one has to be set. ",
-			"Please, change configuration then retry.",
-			)
-		return true
-	}
-	// miss password
-	if plan.Ssl_certificate.Data.Value != "" && plan.Ssl_certificate.Password.Value == "" {
-		resp.Diagnostics.AddError(
-			"Unable to update binding. In the SSL Certificate  ("+plan.Ssl_certificate.Name.Value+") configuration, Data parameter (pfx file content) "+ 
-			"is provided without password. ",
+	if checkStringConfigured(sslCertificate.Data) && checkStringMissing(sslCertificate.Password) {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("password"),
+			"Invalid SSL Certificate: "+sslCertificate.Name.Value+", the data (pfx file content) is provided without password.",
 			"Please, add password then retry.",
-			)
-		return true
+		)
 	}
-	return false
-}
-func base64EncodeIfNot(data string) string {
-	// Check whether the data is already Base64 encoded; don't double-encode
-	if base64IsEncoded(data) {
-		return data
+	if checkStringConfigured(sslCertificate.Key_vault_secret_id) {
+		if _, err := parseKeyVaultSecretID(sslCertificate.Key_vault_secret_id.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("key_vault_secret_id"),
+				"Invalid SSL Certificate: "+sslCertificate.Name.Value+", "+err.Error()+".",
+				"Please, change the key_vault_secret_id then retry.",
+			)
+		}
 	}
-	// data has not been encoded encode and return
-	return base64.StdEncoding.EncodeToString([]byte(data))
 }
-func base64IsEncoded(data string) bool {
-	_, err := base64.StdEncoding.DecodeString(data)
-	return err == nil
-}
\ No newline at end of file
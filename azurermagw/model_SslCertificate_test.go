@@ -0,0 +1,135 @@
+package azurermagw
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The fixtures of testdata were generated with OpenSSL, the certificates are valid for 100 years:
+//	openssl req -x509 -newkey rsa:2048 -nodes -keyout ca.key -out ca.pem -days 36500 -subj "/CN=azurermagw test CA" \
+//		-addext "basicConstraints=critical,CA:TRUE"
+//	openssl req -newkey rsa:2048 -nodes -keyout server.key -out server.csr -subj "/CN=app1.example.com"
+//	openssl x509 -req -in server.csr -CA ca.pem -CAkey ca.key -CAcreateserial -out server.pem -days 36500
+//	openssl req -x509 -newkey rsa:2048 -nodes -keyout other.key -out other.pem -days 36500 -subj "/CN=other.example.com"
+//	openssl pkcs12 -export -legacy -inkey server.key -in server.pem -certfile ca.pem -passout pass:test-password -out server.pfx
+//	openssl pkcs12 -export -inkey server.key -in server.pem -certfile ca.pem -passout pass:test-password -out server_aes.pfx
+//	openssl pkcs12 -export -legacy -nokeys -in server.pem -certfile ca.pem -passout pass:test-password -out server_nokey.pfx
+//	openssl crl2pkcs7 -nocrl -certfile server.pem -certfile ca.pem -outform DER -out server.p7b
+//	openssl crl2pkcs7 -nocrl -certfile other.pem -outform DER -out other.p7b
+//	openssl x509 -in server.pem -outform DER -out server.cer
+const testPFXPassword = "test-password"
+
+func readTestFixture(t *testing.T, name string) string {
+	//return the base64 encoded content of the fixture, like filebase64() or the publicCertData of the gateway
+	content, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("the fixture %s can't be read: %s", name, err)
+	}
+	return base64.StdEncoding.EncodeToString(content)
+}
+
+func TestParsePFX(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		data     string
+		password string
+		//wrap the data with line breaks, like a file read without trimming it
+		wrap bool
+		err  string
+	}{
+		{name: "legacy PFX", fixture: "server.pfx", password: testPFXPassword},
+		{name: "AES PFX", fixture: "server_aes.pfx", password: testPFXPassword},
+		{name: "data with line breaks around it", fixture: "server.pfx", password: testPFXPassword, wrap: true},
+		{name: "wrong password", fixture: "server.pfx", password: "wrong-password", err: "the password is incorrect"},
+		{name: "empty password", fixture: "server.pfx", password: "", err: "the password is incorrect"},
+		{name: "no private key", fixture: "server_nokey.pfx", password: testPFXPassword, err: "it has to contain a private key"},
+		{name: "not base64 encoded", data: "not a PFX!", password: testPFXPassword, err: "the data has to be the base64 encoded content of the PFX file"},
+		{name: "not a PFX", fixture: "server.cer", password: testPFXPassword, err: "the PFX can't be decoded"},
+	}
+	for _, test := range tests {
+		data := test.data
+		if test.fixture != "" {
+			data = readTestFixture(t, test.fixture)
+		}
+		if test.wrap {
+			data = "\n" + data + "\n"
+		}
+		certificate, err := parsePFX(data, test.password)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: error = %v, want %q", test.name, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: the PFX can't be parsed: %s", test.name, err)
+			continue
+		}
+		//the certificate of the private key is returned, not the CA certificate of the chain
+		if certificate.Subject.CommonName != "app1.example.com" {
+			t.Errorf("%s: the certificate is %s, want CN=app1.example.com", test.name, certificate.Subject)
+		}
+	}
+}
+
+func TestParsePublicCertData(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		certificates int
+		server       string
+		valid        bool
+	}{
+		{"PKCS#7 with the chain", readTestFixture(t, "server.p7b"), 2, "app1.example.com", true},
+		{"PKCS#7 with a self-signed certificate", readTestFixture(t, "other.p7b"), 1, "other.example.com", true},
+		{"DER encoded certificate", readTestFixture(t, "server.cer"), 1, "app1.example.com", true},
+		{"not base64 encoded", "not a certificate!", 0, "", false},
+		{"not a certificate", base64.StdEncoding.EncodeToString([]byte("not a certificate")), 0, "", false},
+	}
+	for _, test := range tests {
+		certificates, err := parsePublicCertData(test.data)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error = %v, want valid = %v", test.name, err, test.valid)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if len(certificates) != test.certificates {
+			t.Errorf("%s: %d certificates, want %d", test.name, len(certificates), test.certificates)
+		}
+		//the server certificate is the one that is not a CA, whatever its position in the structure
+		certificate, err := getPublicCertDataCertificate(test.data)
+		if err != nil || certificate.Subject.CommonName != test.server {
+			t.Errorf("%s: the server certificate is %v (%v), want CN=%s", test.name, certificate, err, test.server)
+		}
+	}
+}
+
+func TestCheckPFXPublicCertData(t *testing.T) {
+	pfx := readTestFixture(t, "server.pfx")
+	tests := []struct {
+		name           string
+		data           string
+		password       string
+		publicCertData string
+		match          bool
+	}{
+		{"matching public certificate data", pfx, testPFXPassword, readTestFixture(t, "server.p7b"), true},
+		{"matching DER certificate", pfx, testPFXPassword, readTestFixture(t, "server.cer"), true},
+		{"AES PFX", readTestFixture(t, "server_aes.pfx"), testPFXPassword, readTestFixture(t, "server.p7b"), true},
+		{"mismatching public certificate data", pfx, testPFXPassword, readTestFixture(t, "other.p7b"), false},
+		{"wrong password", pfx, "wrong-password", readTestFixture(t, "server.p7b"), false},
+		{"PFX without private key", readTestFixture(t, "server_nokey.pfx"), testPFXPassword, readTestFixture(t, "server.p7b"), false},
+		{"empty public certificate data", pfx, testPFXPassword, "", false},
+	}
+	for _, test := range tests {
+		if match := checkPFXPublicCertData(test.data, test.password, test.publicCertData); match != test.match {
+			t.Errorf("%s: match = %v, want %v", test.name, match, test.match)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package azurermagw
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type SslProfile struct {
+	Name       string `json:"name,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Etag       string `json:"etag,omitempty"`
+	Properties struct {
+		ProvisioningState       string `json:"provisioningState,omitempty"`
+		ClientAuthConfiguration *struct {
+			VerifyClientCertIssuerDN bool `json:"verifyClientCertIssuerDN"`
+		} `json:"clientAuthConfiguration,omitempty"`
+		SslPolicy *struct {
+			CipherSuites         []string `json:"cipherSuites,omitempty"`
+			DisabledSslProtocols []string `json:"disabledSslProtocols,omitempty"`
+			MinProtocolVersion   string   `json:"minProtocolVersion,omitempty"`
+			PolicyName           string   `json:"policyName,omitempty"`
+			PolicyType           string   `json:"policyType,omitempty"`
+		} `json:"sslPolicy,omitempty"`
+		TrustedClientCertificates []struct {
+			ID string `json:"id,omitempty"`
+		} `json:"trustedClientCertificates,omitempty"`
+	} `json:"properties"`
+	Type string `json:"type,omitempty"`
+}
+
+type Ssl_profile struct {
+	//required
+	Name         						types.String	`tfsdk:"name"`
+	Id           						types.String	`tfsdk:"id"`
+	//optional
+	Ssl_policy							*Ssl_policy		`tfsdk:"ssl_policy"`
+	Trusted_client_certificate_names	[]types.String	`tfsdk:"trusted_client_certificate_names"`
+	Verify_client_cert_issuer_dn		types.Bool		`tfsdk:"verify_client_cert_issuer_dn"`	//default to false
+}
+
+type Ssl_policy struct {
+	//required
+	Policy_type							types.String	`tfsdk:"policy_type"`
+	//only for the Predefined policy type
+	Policy_name							types.String	`tfsdk:"policy_name"`
+	//only for the Custom and CustomV2 policy types
+	Min_protocol_version				types.String	`tfsdk:"min_protocol_version"`
+	Cipher_suites						[]types.String	`tfsdk:"cipher_suites"`
+}
+
+// the values accepted by Azure for the ssl policies
+var sslPolicyTypes = []string{"Predefined", "Custom", "CustomV2"}
+var sslPolicyNames = []string{"AppGwSslPolicy20150501", "AppGwSslPolicy20170401", "AppGwSslPolicy20170401S", "AppGwSslPolicy20220101", "AppGwSslPolicy20220101S"}
+var sslProtocolVersions = []string{"TLSv1_0", "TLSv1_1", "TLSv1_2", "TLSv1_3"}
+
+func createSslProfile(sslProfile_plan Ssl_profile, AZURE_SUBSCRIPTION_ID string, rg_name string, agw_name string) SslProfile {
+	sslProfile_json := SslProfile{
+		Name: sslProfile_plan.Name.Value,
+		Type: "Microsoft.Network/applicationGateways/sslProfiles",
+	}
+	if sslProfile_plan.Ssl_policy != nil {
+		//ssl_policy is set
+		sslProfile_json.Properties.SslPolicy = &struct{
+			CipherSuites []string "json:\"cipherSuites,omitempty\""
+			DisabledSslProtocols []string "json:\"disabledSslProtocols,omitempty\""
+			MinProtocolVersion string "json:\"minProtocolVersion,omitempty\""
+			PolicyName string "json:\"policyName,omitempty\""
+			PolicyType string "json:\"policyType,omitempty\""
+		}{
+			PolicyType: sslProfile_plan.Ssl_policy.Policy_type.Value,
+		}
+		if sslProfile_plan.Ssl_policy.Policy_type.Value == "Predefined" {
+			sslProfile_json.Properties.SslPolicy.PolicyName = sslProfile_plan.Ssl_policy.Policy_name.Value
+		}else{
+			sslProfile_json.Properties.SslPolicy.MinProtocolVersion = sslProfile_plan.Ssl_policy.Min_protocol_version.Value
+			sslProfile_json.Properties.SslPolicy.CipherSuites = make([]string, len(sslProfile_plan.Ssl_policy.Cipher_suites))
+			for i := 0; i < len(sslProfile_plan.Ssl_policy.Cipher_suites); i++ {
+				sslProfile_json.Properties.SslPolicy.CipherSuites[i] = sslProfile_plan.Ssl_policy.Cipher_suites[i].Value
+			}
+		}
+	}
+	//the client authentication is enabled when trusted client certificates are set
+	if len(sslProfile_plan.Trusted_client_certificate_names) != 0 {
+		trustedClientCertificateID := "/subscriptions/"+AZURE_SUBSCRIPTION_ID+"/resourceGroups/"+rg_name+
+		"/providers/Microsoft.Network/applicationGateways/"+agw_name+"/trustedClientCertificates/"
+		sslProfile_json.Properties.TrustedClientCertificates = make([]struct{ID string "json:\"id,omitempty\""}, len(sslProfile_plan.Trusted_client_certificate_names))
+		for i := 0; i < len(sslProfile_plan.Trusted_client_certificate_names); i++ {
+			sslProfile_json.Properties.TrustedClientCertificates[i].ID = trustedClientCertificateID + sslProfile_plan.Trusted_client_certificate_names[i].Value
+		}
+		sslProfile_json.Properties.ClientAuthConfiguration = &struct{
+			VerifyClientCertIssuerDN bool "json:\"verifyClientCertIssuerDN\""
+		}{
+			VerifyClientCertIssuerDN: sslProfile_plan.Verify_client_cert_issuer_dn.Value,
+		}
+	}
+	return sslProfile_json
+}
+func generateSslProfileState(gw ApplicationGateway, SslProfileName string) Ssl_profile {
+	//retrieve json element from gw
+	index := getSslProfileElementKey_gw(gw, SslProfileName)
+	sslProfile_json := gw.Properties.SslProfiles[index]
+
+	// Map response body to resource schema attribute
+	var sslProfile_state Ssl_profile
+	sslProfile_state = Ssl_profile{
+		Name: types.String{Value: sslProfile_json.Name},
+		Id:   types.String{Value: sslProfile_json.ID},
+		Verify_client_cert_issuer_dn: types.Bool{Value: false},
+	}
+	//map Trusted_client_certificate_names. the names are the last part of the IDs
+	if len(sslProfile_json.Properties.TrustedClientCertificates) != 0 {
+		sslProfile_state.Trusted_client_certificate_names = make([]types.String, len(sslProfile_json.Properties.TrustedClientCertificates))
+		for i := 0; i < len(sslProfile_json.Properties.TrustedClientCertificates); i++ {
+			splitted_list := strings.Split(sslProfile_json.Properties.TrustedClientCertificates[i].ID,"/")
+			sslProfile_state.Trusted_client_certificate_names[i] = types.String{Value: splitted_list[len(splitted_list)-1]}
+		}
+	}
+	if sslProfile_json.Properties.ClientAuthConfiguration != nil {
+		sslProfile_state.Verify_client_cert_issuer_dn = types.Bool{Value: sslProfile_json.Properties.ClientAuthConfiguration.VerifyClientCertIssuerDN}
+	}
+	if sslProfile_json.Properties.SslPolicy != nil {
+		sslPolicy_json := sslProfile_json.Properties.SslPolicy
+		sslProfile_state.Ssl_policy = &Ssl_policy{
+			Policy_type:          types.String{Value: sslPolicy_json.PolicyType},
+			Policy_name:          types.String{Null: true},
+			Min_protocol_version: types.String{Null: true},
+		}
+		//only the attributes used by the policy type are mapped, the other ones are computed by Azure
+		if sslPolicy_json.PolicyType == "Predefined" {
+			sslProfile_state.Ssl_policy.Policy_name = types.String{Value: sslPolicy_json.PolicyName}
+		}else{
+			if sslPolicy_json.MinProtocolVersion != "" {
+				sslProfile_state.Ssl_policy.Min_protocol_version = types.String{Value: sslPolicy_json.MinProtocolVersion}
+			}
+			if len(sslPolicy_json.CipherSuites) != 0 {
+				sslProfile_state.Ssl_policy.Cipher_suites = make([]types.String, len(sslPolicy_json.CipherSuites))
+				for i := 0; i < len(sslPolicy_json.CipherSuites); i++ {
+					sslProfile_state.Ssl_policy.Cipher_suites[i] = types.String{Value: sslPolicy_json.CipherSuites[i]}
+				}
+			}
+		}
+	}
+	return sslProfile_state
+}
+func getSslProfileElementKey_gw(gw ApplicationGateway, SslProfileName string) int {
+	key := -1
+	for i := len(gw.Properties.SslProfiles) - 1; i >= 0; i-- {
+		if gw.Properties.SslProfiles[i].Name == SslProfileName {
+			key = i
+		}
+	}
+	return key
+}
+func checkSslProfileElement(gw ApplicationGateway, SslProfileName string) bool {
+	exist := false
+	for i := len(gw.Properties.SslProfiles) - 1; i >= 0; i-- {
+		if gw.Properties.SslProfiles[i].Name == SslProfileName {
+			exist = true
+		}
+	}
+	return exist
+}
+func removeSslProfileElement(gw *ApplicationGateway, SslProfileName string) {
+	for i := len(gw.Properties.SslProfiles) - 1; i >= 0; i-- {
+		if gw.Properties.SslProfiles[i].Name == SslProfileName {
+			gw.Properties.SslProfiles = append(gw.Properties.SslProfiles[:i], gw.Properties.SslProfiles[i+1:]...)
+		}
+	}
+}
+func checkSslProfileNameInMap(SslProfileName string, ssl_profiles map[string]Ssl_profile) bool {
+	for _, value := range ssl_profiles {
+		if SslProfileName == value.Name.Value {
+			return true
+		}
+	}
+	return false
+}
+func checkSslProfileConfig(sslProfile_plan Ssl_profile, plan BindingService, gw ApplicationGateway) (string, bool) {
+	//return the error message when the ssl profile doesn't satisfy the constraints, and true
+	if !checkApplicationGatewayV2(gw) {
+		return "The SSL profile ("+sslProfile_plan.Name.Value+") can't be added because the tier of the gateway ("+gw.Properties.Sku.Tier+
+			") is not a v2 one (Standard_v2 or WAF_v2). ", true
+	}
+	//the trusted client certificates have to be declared in the binding or to already exist in the gateway
+	for _, name := range sslProfile_plan.Trusted_client_certificate_names {
+		if !checkTrustedClientCertificateNameInMap(name.Value, plan.Trusted_client_certificates) &&
+			!checkTrustedClientCertificateElement(gw, name.Value) {
+			return "The trusted client certificate name ("+name.Value+") of the SSL profile ("+sslProfile_plan.Name.Value+") doesn't match "+
+				"any trusted client certificate declared in the binding or existing in the application gateway. ", true
+		}
+	}
+	if sslProfile_plan.Verify_client_cert_issuer_dn.Value && len(sslProfile_plan.Trusted_client_certificate_names) == 0 {
+		return "In the SSL profile ("+sslProfile_plan.Name.Value+"), verify_client_cert_issuer_dn can only be enabled "+
+			"when trusted client certificates are set. ", true
+	}
+	sslPolicy_plan := sslProfile_plan.Ssl_policy
+	if sslPolicy_plan == nil {
+		return "", false
+	}
+	//the values of the policy type, the policy name and the min protocol version are checked by the validators of the schema
+	if sslPolicy_plan.Policy_type.Value == "Predefined" {
+		if sslPolicy_plan.Policy_name.Value == "" {
+			return "In the SSL profile ("+sslProfile_plan.Name.Value+"), policy_name is required when the policy type is Predefined. ", true
+		}
+		if sslPolicy_plan.Min_protocol_version.Value != "" || len(sslPolicy_plan.Cipher_suites) != 0 {
+			return "In the SSL profile ("+sslProfile_plan.Name.Value+"), min_protocol_version and cipher_suites cannot be set "+
+				"when the policy type is Predefined. ", true
+		}
+		return "", false
+	}
+	//Custom and CustomV2 policy types
+	if sslPolicy_plan.Policy_name.Value != "" {
+		return "In the SSL profile ("+sslProfile_plan.Name.Value+"), policy_name can only be set when the policy type is Predefined. ", true
+	}
+	if sslPolicy_plan.Min_protocol_version.Value == "" {
+		return "In the SSL profile ("+sslProfile_plan.Name.Value+"), min_protocol_version is required for a "+
+			sslPolicy_plan.Policy_type.Value+" policy. ", true
+	}
+	if sslPolicy_plan.Policy_type.Value == "Custom" && sslPolicy_plan.Min_protocol_version.Value == "TLSv1_3" {
+		return "In the SSL profile ("+sslProfile_plan.Name.Value+"), TLSv1_3 is only supported by the CustomV2 policy type. ", true
+	}
+	if len(sslPolicy_plan.Cipher_suites) == 0 && sslPolicy_plan.Min_protocol_version.Value != "TLSv1_3" {
+		return "In the SSL profile ("+sslProfile_plan.Name.Value+"), at least one cipher suite has to be set for a "+
+			sslPolicy_plan.Policy_type.Value+" policy. ", true
+	}
+	return "", false
+}
+func checkSslProfileCreate(sslProfile_plan Ssl_profile, plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
+	if err, fail := checkSslProfileConfig(sslProfile_plan, plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. "+err,
+			"Please, change SSL profile configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
+func checkSslProfileUpdate(sslProfile_plan Ssl_profile, plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
+	if err, fail := checkSslProfileConfig(sslProfile_plan, plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. "+err,
+			"Please, change SSL profile configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
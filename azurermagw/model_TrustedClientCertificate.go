@@ -0,0 +1,172 @@
+package azurermagw
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type TrustedClientCertificate struct {
+	Name       string `json:"name,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Etag       string `json:"etag,omitempty"`
+	Properties struct {
+		ProvisioningState  string `json:"provisioningState,omitempty"`
+		Data               string `json:"data,omitempty"`
+		ClientCertIssuerDN string `json:"clientCertIssuerDN,omitempty"`
+		ValidatedCertData  string `json:"validatedCertData,omitempty"`
+	} `json:"properties"`
+	Type string `json:"type,omitempty"`
+}
+
+type Trusted_client_certificate struct {
+	//required
+	Name         						types.String	`tfsdk:"name"`
+	Id           						types.String	`tfsdk:"id"`
+	Data								types.String	`tfsdk:"data"`
+}
+
+func createTrustedClientCertificate(trustedClientCertificate_plan Trusted_client_certificate) TrustedClientCertificate {
+	trustedClientCertificate_json := TrustedClientCertificate{
+		Name: trustedClientCertificate_plan.Name.Value,
+		Type: "Microsoft.Network/applicationGateways/trustedClientCertificates",
+	}
+	//the data (PEM or base64 encoded PEM) was already parsed by checkTrustedClientCertificateConfig
+	certificates, _ := parsePEMCertificates(trustedClientCertificate_plan.Data.Value)
+	trustedClientCertificate_json.Properties.Data = encodePEMCertificates(certificates)
+	return trustedClientCertificate_json
+}
+func generateTrustedClientCertificateState(gw ApplicationGateway, TrustedClientCertificateName string, data types.String) Trusted_client_certificate {
+	//retrieve json element from gw
+	index := getTrustedClientCertificateElementKey_gw(gw, TrustedClientCertificateName)
+	trustedClientCertificate_json := gw.Properties.TrustedClientCertificates[index]
+
+	// Map response body to resource schema attribute
+	//the data returned by the gateway is reformatted, so the configured data is kept as it is
+	var trustedClientCertificate_state Trusted_client_certificate
+	trustedClientCertificate_state = Trusted_client_certificate{
+		Name: types.String{Value: trustedClientCertificate_json.Name},
+		Id:   types.String{Value: trustedClientCertificate_json.ID},
+		Data: data,
+	}
+	return trustedClientCertificate_state
+}
+func getTrustedClientCertificateElementKey_gw(gw ApplicationGateway, TrustedClientCertificateName string) int {
+	key := -1
+	for i := len(gw.Properties.TrustedClientCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.TrustedClientCertificates[i].Name == TrustedClientCertificateName {
+			key = i
+		}
+	}
+	return key
+}
+func checkTrustedClientCertificateElement(gw ApplicationGateway, TrustedClientCertificateName string) bool {
+	exist := false
+	for i := len(gw.Properties.TrustedClientCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.TrustedClientCertificates[i].Name == TrustedClientCertificateName {
+			exist = true
+		}
+	}
+	return exist
+}
+func removeTrustedClientCertificateElement(gw *ApplicationGateway, TrustedClientCertificateName string) {
+	for i := len(gw.Properties.TrustedClientCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.TrustedClientCertificates[i].Name == TrustedClientCertificateName {
+			gw.Properties.TrustedClientCertificates = append(gw.Properties.TrustedClientCertificates[:i], gw.Properties.TrustedClientCertificates[i+1:]...)
+		}
+	}
+}
+func checkTrustedClientCertificateNameInMap(TrustedClientCertificateName string, trusted_client_certificates map[string]Trusted_client_certificate) bool {
+	for _, value := range trusted_client_certificates {
+		if TrustedClientCertificateName == value.Name.Value {
+			return true
+		}
+	}
+	return false
+}
+func parsePEMCertificates(data string) ([]*x509.Certificate, error) {
+	//the data can be the PEM content itself or its base64 encoding
+	pem_data := []byte(data)
+	if !strings.Contains(data, "-----BEGIN") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+		if err != nil {
+			return nil, errors.New("the data is neither PEM nor base64 encoded PEM")
+		}
+		pem_data = decoded
+	}
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, pem_data = pem.Decode(pem_data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, errors.New("the PEM block of type "+block.Type+" is not a certificate")
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.New("a certificate can't be parsed: "+err.Error())
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, errors.New("no PEM encoded certificate was found")
+	}
+	if strings.TrimSpace(string(pem_data)) != "" {
+		return nil, errors.New("the data contains some content that is not PEM encoded")
+	}
+	return certificates, nil
+}
+func encodePEMCertificates(certificates []*x509.Certificate) string {
+	//the gateway expects the base64 encoded content of the PEM file
+	var pem_data []byte
+	for _, certificate := range certificates {
+		pem_data = append(pem_data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})...)
+	}
+	return base64.StdEncoding.EncodeToString(pem_data)
+}
+func checkTrustedClientCertificateConfig(trustedClientCertificate_plan Trusted_client_certificate, gw ApplicationGateway) (string, bool) {
+	//return the error message when the trusted client certificate doesn't satisfy the constraints, and true
+	if !checkApplicationGatewayV2(gw) {
+		return "The trusted client certificate ("+trustedClientCertificate_plan.Name.Value+") can't be added because the tier of the gateway ("+
+			gw.Properties.Sku.Tier+") is not a v2 one (Standard_v2 or WAF_v2). ", true
+	}
+	certificates, err := parsePEMCertificates(trustedClientCertificate_plan.Data.Value)
+	if err != nil {
+		return "The data of the trusted client certificate ("+trustedClientCertificate_plan.Name.Value+") is not valid: "+err.Error()+". ", true
+	}
+	//the gateway only accepts CA certificates to verify the client certificates
+	for _, certificate := range certificates {
+		if !certificate.IsCA {
+			return "The trusted client certificate ("+trustedClientCertificate_plan.Name.Value+") contains a certificate ("+
+				certificate.Subject.String()+") that is not a CA certificate. ", true
+		}
+	}
+	return "", false
+}
+func checkTrustedClientCertificateCreate(trustedClientCertificate_plan Trusted_client_certificate, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
+	if err, fail := checkTrustedClientCertificateConfig(trustedClientCertificate_plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. "+err,
+			"Please, change trusted client certificate configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
+func checkTrustedClientCertificateUpdate(trustedClientCertificate_plan Trusted_client_certificate, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
+	if err, fail := checkTrustedClientCertificateConfig(trustedClientCertificate_plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. "+err,
+			"Please, change trusted client certificate configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package azurermagw
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type TrustedRootCertificate struct {
+	Name       string `json:"name,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Etag       string `json:"etag,omitempty"`
+	Properties struct {
+		ProvisioningState string `json:"provisioningState,omitempty"`
+		Data              string `json:"data,omitempty"`
+		KeyVaultSecretID  string `json:"keyVaultSecretId,omitempty"`
+	} `json:"properties"`
+	Type string `json:"type,omitempty"`
+}
+
+// TrustedRootCertificate resource
+type Trusted_root_certificate struct {
+	//required
+	Name                 		types.String         			`tfsdk:"name"`
+	Id                 			types.String         			`tfsdk:"id"`
+	Agw_name             		types.String         			`tfsdk:"application_gateway_name"`
+	Agw_rg               		types.String         			`tfsdk:"application_gateway_resource_group_name"`
+	//optional but mutually exclusive
+	Data						types.String					`tfsdk:"data"`
+	Key_vault_secret_id			types.String					`tfsdk:"key_vault_secret_id"`
+}
+
+func createTrustedRootCertificate(trustedRootCertificate_plan Trusted_root_certificate) TrustedRootCertificate {
+	trustedRootCertificate_json := TrustedRootCertificate{
+		Name: trustedRootCertificate_plan.Name.Value,
+		Type: "Microsoft.Network/applicationGateways/trustedRootCertificates",
+	}
+	if trustedRootCertificate_plan.Key_vault_secret_id.Value != "" {
+		trustedRootCertificate_json.Properties.KeyVaultSecretID = trustedRootCertificate_plan.Key_vault_secret_id.Value
+	}else{
+		//the data (PEM, base64 encoded PEM or DER) was already parsed by checkTrustedRootCertificateConfig
+		certificates, _ := parseCertificateData(trustedRootCertificate_plan.Data.Value)
+		trustedRootCertificate_json.Properties.Data = encodePEMCertificates(certificates)
+	}
+	return trustedRootCertificate_json
+}
+func generateTrustedRootCertificateState(gw ApplicationGateway, trustedRootCertificate_prior Trusted_root_certificate) Trusted_root_certificate {
+	//retrieve json element from gw
+	index := getTrustedRootCertificateElementKey_gw(gw, trustedRootCertificate_prior.Name.Value)
+	trustedRootCertificate_json := gw.Properties.TrustedRootCertificates[index]
+
+	// Map response body to resource schema attribute
+	var trustedRootCertificate_state Trusted_root_certificate
+	trustedRootCertificate_state = Trusted_root_certificate{
+		Name:     types.String{Value: trustedRootCertificate_json.Name},
+		Id:       types.String{Value: trustedRootCertificate_json.ID},
+		Agw_name: types.String{Value: gw.Name},
+		Agw_rg:   trustedRootCertificate_prior.Agw_rg,
+	}
+	if trustedRootCertificate_json.Properties.KeyVaultSecretID != "" {
+		trustedRootCertificate_state.Key_vault_secret_id = types.String{Value: trustedRootCertificate_json.Properties.KeyVaultSecretID}
+		trustedRootCertificate_state.Data = types.String{Null: true}
+		return trustedRootCertificate_state
+	}
+	trustedRootCertificate_state.Key_vault_secret_id = types.String{Null: true}
+	//the gateway reformats the data, so the prior data is kept as long as it holds the same certificate
+	if trustedRootCertificate_prior.Data.Value != "" &&
+		checkSameCertificateData(trustedRootCertificate_prior.Data.Value, trustedRootCertificate_json.Properties.Data) {
+		trustedRootCertificate_state.Data = trustedRootCertificate_prior.Data
+	}else{
+		trustedRootCertificate_state.Data = types.String{Value: trustedRootCertificate_json.Properties.Data}
+	}
+	return trustedRootCertificate_state
+}
+func getTrustedRootCertificateElementKey_gw(gw ApplicationGateway, TrustedRootCertificateName string) int {
+	key := -1
+	for i := len(gw.Properties.TrustedRootCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.TrustedRootCertificates[i].Name == TrustedRootCertificateName {
+			key = i
+		}
+	}
+	return key
+}
+func checkTrustedRootCertificateElement(gw ApplicationGateway, TrustedRootCertificateName string) bool {
+	exist := false
+	for i := len(gw.Properties.TrustedRootCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.TrustedRootCertificates[i].Name == TrustedRootCertificateName {
+			exist = true
+		}
+	}
+	return exist
+}
+func removeTrustedRootCertificateElement(gw *ApplicationGateway, TrustedRootCertificateName string) {
+	for i := len(gw.Properties.TrustedRootCertificates) - 1; i >= 0; i-- {
+		if gw.Properties.TrustedRootCertificates[i].Name == TrustedRootCertificateName {
+			gw.Properties.TrustedRootCertificates = append(gw.Properties.TrustedRootCertificates[:i], gw.Properties.TrustedRootCertificates[i+1:]...)
+		}
+	}
+}
+func parseCertificateData(data string) ([]*x509.Certificate, error) {
+	//the data can be PEM, base64 encoded PEM or base64 encoded DER (.cer file)
+	certificates, err := parsePEMCertificates(data)
+	if err == nil {
+		return certificates, nil
+	}
+	decoded, decode_err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if decode_err != nil {
+		return nil, err
+	}
+	certificate, der_err := x509.ParseCertificate(decoded)
+	if der_err != nil {
+		return nil, err
+	}
+	return []*x509.Certificate{certificate}, nil
+}
+func checkSameCertificateData(data1 string, data2 string) bool {
+	certificates1, err := parseCertificateData(data1)
+	if err != nil {
+		return false
+	}
+	certificates2, err := parseCertificateData(data2)
+	if err != nil || len(certificates1) != len(certificates2) {
+		return false
+	}
+	for i := 0; i < len(certificates1); i++ {
+		if !certificates1[i].Equal(certificates2[i]) {
+			return false
+		}
+	}
+	return true
+}
+func checkTrustedRootCertificateConfig(trustedRootCertificate_plan Trusted_root_certificate, gw ApplicationGateway) (string, bool) {
+	//return the error message when the trusted root certificate doesn't satisfy the constraints, and true
+	if !checkApplicationGatewayV2(gw) {
+		return "The trusted root certificate ("+trustedRootCertificate_plan.Name.Value+") can't be added because the tier of the gateway ("+
+			gw.Properties.Sku.Tier+") is not a v2 one (Standard_v2 or WAF_v2). ", true
+	}
+	if (trustedRootCertificate_plan.Data.Value == "") == (trustedRootCertificate_plan.Key_vault_secret_id.Value == "") {
+		return "For the trusted root certificate ("+trustedRootCertificate_plan.Name.Value+"), data and key_vault_secret_id are mutually exclusive. "+
+			"Exactly one should be set. ", true
+	}
+	if trustedRootCertificate_plan.Key_vault_secret_id.Value != "" {
+		if _, err := parseKeyVaultSecretID(trustedRootCertificate_plan.Key_vault_secret_id.Value); err != nil {
+			return "The key_vault_secret_id of the trusted root certificate ("+trustedRootCertificate_plan.Name.Value+") is not valid: "+err.Error()+". ", true
+		}
+	}
+	if trustedRootCertificate_plan.Data.Value != "" {
+		if _, err := parseCertificateData(trustedRootCertificate_plan.Data.Value); err != nil {
+			return "The data of the trusted root certificate ("+trustedRootCertificate_plan.Name.Value+") is not valid: "+err.Error()+". ", true
+		}
+	}
+	return "", false
+}
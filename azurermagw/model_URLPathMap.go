@@ -0,0 +1,405 @@
+package azurermagw
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+type URLPathMap struct {
+	Name       string `json:"name,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Etag       string `json:"etag,omitempty"`
+	Properties struct {
+		ProvisioningState         string `json:"provisioningState,omitempty"`
+		DefaultBackendAddressPool *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"defaultBackendAddressPool,omitempty"`
+		DefaultBackendHTTPSettings *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"defaultBackendHttpSettings,omitempty"`
+		DefaultLoadDistributionPolicy *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"defaultLoadDistributionPolicy,omitempty"`
+		DefaultRedirectConfiguration *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"defaultRedirectConfiguration,omitempty"`
+		DefaultRewriteRuleSet *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"defaultRewriteRuleSet,omitempty"`
+		PathRules           []PathRule `json:"pathRules"`
+		RequestRoutingRules *[]struct {
+			ID string `json:"id,omitempty"`
+		} `json:"requestRoutingRules,omitempty"`
+	} `json:"properties"`
+	Type string `json:"type,omitempty"`
+}
+
+type PathRule struct {
+	Name       string `json:"name,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Etag       string `json:"etag,omitempty"`
+	Properties struct {
+		ProvisioningState  string   `json:"provisioningState,omitempty"`
+		Paths              []string `json:"paths"`
+		BackendAddressPool *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"backendAddressPool,omitempty"`
+		BackendHTTPSettings *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"backendHttpSettings,omitempty"`
+		FirewallPolicy *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"firewallPolicy,omitempty"`
+		LoadDistributionPolicy *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"loadDistributionPolicy,omitempty"`
+		RedirectConfiguration *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"redirectConfiguration,omitempty"`
+		RewriteRuleSet *struct {
+			ID string `json:"id,omitempty"`
+		} `json:"rewriteRuleSet,omitempty"`
+	} `json:"properties"`
+	Type string `json:"type,omitempty"`
+}
+
+type Url_path_map struct {
+	//required
+	Name         						types.String	`tfsdk:"name"`
+	Id           						types.String	`tfsdk:"id"`
+	//Cannot be set if default_redirect_configuration_name is set
+	Default_backend_address_pool_name	types.String	`tfsdk:"default_backend_address_pool_name"`
+	Default_backend_http_settings_name	types.String	`tfsdk:"default_backend_http_settings_name"`
+	//Cannot be set if both default_backend_address_pool_name and default_backend_http_settings_name are set
+	Default_redirect_configuration_name	types.String	`tfsdk:"default_redirect_configuration_name"`
+	//Only valid for v2 SKUs.
+	Default_rewrite_rule_set_name		types.String	`tfsdk:"default_rewrite_rule_set_name"`
+	Path_rules							map[string]Path_rule	`tfsdk:"path_rules"`
+}
+
+type Path_rule struct {
+	//required
+	Name         						types.String	`tfsdk:"name"`
+	Id           						types.String	`tfsdk:"id"`
+	Paths								[]types.String	`tfsdk:"paths"`
+	//Cannot be set if redirect_configuration_name is set
+	Backend_address_pool_name			types.String	`tfsdk:"backend_address_pool_name"`
+	Backend_http_settings_name			types.String	`tfsdk:"backend_http_settings_name"`
+	//Cannot be set if both backend_address_pool_name and backend_http_settings_name are set
+	Redirect_configuration_name			types.String	`tfsdk:"redirect_configuration_name"`
+	//Only valid for v2 SKUs.
+	Rewrite_rule_set_name				types.String	`tfsdk:"rewrite_rule_set_name"`
+	//optional
+	Firewall_policy_id					types.String	`tfsdk:"firewall_policy_id"`
+}
+
+func createURLPathMap(urlPathMap_plan Url_path_map, AZURE_SUBSCRIPTION_ID string, rg_name string, agw_name string) URLPathMap {
+	urlPathMap_json := URLPathMap{
+		Name: urlPathMap_plan.Name.Value,
+		Type: "Microsoft.Network/applicationGateways/urlPathMaps",
+	}
+	ID := "/subscriptions/"+AZURE_SUBSCRIPTION_ID+"/resourceGroups/"+rg_name+"/providers/Microsoft.Network/applicationGateways/"+agw_name
+
+	if urlPathMap_plan.Default_redirect_configuration_name.Value != "" {
+		//default_redirect_configuration_name is set
+		redirectConfigurationID := ID+"/redirectConfigurations/"+urlPathMap_plan.Default_redirect_configuration_name.Value
+		urlPathMap_json.Properties.DefaultRedirectConfiguration = &struct{ID string "json:\"id,omitempty\""}{ID: redirectConfigurationID,}
+	}else{
+		//default_backend_address_pool_name and default_backend_http_settings_name are set
+		backendAddressPoolID := ID+"/backendAddressPools/"+urlPathMap_plan.Default_backend_address_pool_name.Value
+		urlPathMap_json.Properties.DefaultBackendAddressPool = &struct{ID string "json:\"id,omitempty\""}{ID: backendAddressPoolID,}
+
+		backendHttpSettingsID := ID+"/backendHttpSettingsCollection/"+urlPathMap_plan.Default_backend_http_settings_name.Value
+		urlPathMap_json.Properties.DefaultBackendHTTPSettings = &struct{ID string "json:\"id,omitempty\""}{ID: backendHttpSettingsID,}
+	}
+	if urlPathMap_plan.Default_rewrite_rule_set_name.Value != "" {
+		//default_rewrite_rule_set_name is set
+		rewriteRuleSetID := ID+"/rewriteRuleSets/"+urlPathMap_plan.Default_rewrite_rule_set_name.Value
+		urlPathMap_json.Properties.DefaultRewriteRuleSet = &struct{ID string "json:\"id,omitempty\""}{ID: rewriteRuleSetID,}
+	}
+
+	//the path rules are added in the order of their keys, so the generated json is always the same
+	urlPathMap_json.Properties.PathRules = []PathRule{}
+	for _, key := range getPathRuleSortedKeys(urlPathMap_plan.Path_rules) {
+		pathRule_plan := urlPathMap_plan.Path_rules[key]
+		pathRule_json := PathRule{
+			Name: pathRule_plan.Name.Value,
+			Type: "Microsoft.Network/applicationGateways/urlPathMaps/pathRules",
+		}
+		pathRule_json.Properties.Paths = make([]string, len(pathRule_plan.Paths))
+		for i := 0; i < len(pathRule_plan.Paths); i++ {
+			pathRule_json.Properties.Paths[i] = pathRule_plan.Paths[i].Value
+		}
+		if pathRule_plan.Redirect_configuration_name.Value != "" {
+			//redirect_configuration_name is set
+			redirectConfigurationID := ID+"/redirectConfigurations/"+pathRule_plan.Redirect_configuration_name.Value
+			pathRule_json.Properties.RedirectConfiguration = &struct{ID string "json:\"id,omitempty\""}{ID: redirectConfigurationID,}
+		}else{
+			//backend_address_pool_name and backend_http_settings_name are set
+			backendAddressPoolID := ID+"/backendAddressPools/"+pathRule_plan.Backend_address_pool_name.Value
+			pathRule_json.Properties.BackendAddressPool = &struct{ID string "json:\"id,omitempty\""}{ID: backendAddressPoolID,}
+
+			backendHttpSettingsID := ID+"/backendHttpSettingsCollection/"+pathRule_plan.Backend_http_settings_name.Value
+			pathRule_json.Properties.BackendHTTPSettings = &struct{ID string "json:\"id,omitempty\""}{ID: backendHttpSettingsID,}
+		}
+		if pathRule_plan.Rewrite_rule_set_name.Value != "" {
+			//rewrite_rule_set_name is set
+			rewriteRuleSetID := ID+"/rewriteRuleSets/"+pathRule_plan.Rewrite_rule_set_name.Value
+			pathRule_json.Properties.RewriteRuleSet = &struct{ID string "json:\"id,omitempty\""}{ID: rewriteRuleSetID,}
+		}
+		if pathRule_plan.Firewall_policy_id.Value != "" {
+			//firewall_policy_id is set
+			pathRule_json.Properties.FirewallPolicy = &struct{ID string "json:\"id,omitempty\""}{ID: pathRule_plan.Firewall_policy_id.Value,}
+		}
+		urlPathMap_json.Properties.PathRules = append(urlPathMap_json.Properties.PathRules, pathRule_json)
+	}
+	return urlPathMap_json
+}
+func generateURLPathMapState(gw ApplicationGateway, URLPathMapName string, path_rules map[string]Path_rule) Url_path_map {
+	//retrieve json element from gw
+	index := getURLPathMapElementKey_gw(gw, URLPathMapName)
+	urlPathMap_json := gw.Properties.URLPathMaps[index]
+
+	// Map response body to resource schema attribute
+	var urlPathMap_state Url_path_map
+	urlPathMap_state = Url_path_map{
+		Name:                                types.String{Value: urlPathMap_json.Name},
+		Id:                                  types.String{Value: urlPathMap_json.ID},
+		Default_backend_address_pool_name:   types.String{Null: true},
+		Default_backend_http_settings_name:  types.String{Null: true},
+		Default_redirect_configuration_name: types.String{Null: true},
+		Default_rewrite_rule_set_name:       types.String{Null: true},
+	}
+	if urlPathMap_json.Properties.DefaultBackendAddressPool != nil {
+		splitted_list := strings.Split(urlPathMap_json.Properties.DefaultBackendAddressPool.ID,"/")
+		urlPathMap_state.Default_backend_address_pool_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if urlPathMap_json.Properties.DefaultBackendHTTPSettings != nil {
+		splitted_list := strings.Split(urlPathMap_json.Properties.DefaultBackendHTTPSettings.ID,"/")
+		urlPathMap_state.Default_backend_http_settings_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if urlPathMap_json.Properties.DefaultRedirectConfiguration != nil {
+		splitted_list := strings.Split(urlPathMap_json.Properties.DefaultRedirectConfiguration.ID,"/")
+		urlPathMap_state.Default_redirect_configuration_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if urlPathMap_json.Properties.DefaultRewriteRuleSet != nil {
+		splitted_list := strings.Split(urlPathMap_json.Properties.DefaultRewriteRuleSet.ID,"/")
+		urlPathMap_state.Default_rewrite_rule_set_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+
+	//the path rules keep the keys given in the plan (or the state).
+	//a path rule that exists in the gw but not in the map (added manually) is added under its name, so the drift is shown
+	urlPathMap_state.Path_rules = make(map[string]Path_rule, len(urlPathMap_json.Properties.PathRules))
+	for i := 0; i < len(urlPathMap_json.Properties.PathRules); i++ {
+		pathRule_json := urlPathMap_json.Properties.PathRules[i]
+		key := pathRule_json.Name
+		pathRule_state := generatePathRuleState(pathRule_json)
+		for key_map, value := range path_rules {
+			if value.Name.Value == pathRule_json.Name {
+				key = key_map
+				pathRule_state.Firewall_policy_id = getFirewallPolicyIDState(pathRule_state.Firewall_policy_id, value.Firewall_policy_id)
+			}
+		}
+		urlPathMap_state.Path_rules[key] = pathRule_state
+	}
+	return urlPathMap_state
+}
+func generatePathRuleState(pathRule_json PathRule) Path_rule {
+	pathRule_state := Path_rule{
+		Name:                        types.String{Value: pathRule_json.Name},
+		Id:                          types.String{Value: pathRule_json.ID},
+		Paths:                       make([]types.String, len(pathRule_json.Properties.Paths)),
+		Backend_address_pool_name:   types.String{Null: true},
+		Backend_http_settings_name:  types.String{Null: true},
+		Redirect_configuration_name: types.String{Null: true},
+		Rewrite_rule_set_name:       types.String{Null: true},
+		Firewall_policy_id:          types.String{Null: true},
+	}
+	for i := 0; i < len(pathRule_json.Properties.Paths); i++ {
+		pathRule_state.Paths[i] = types.String{Value: pathRule_json.Properties.Paths[i]}
+	}
+	if pathRule_json.Properties.BackendAddressPool != nil {
+		splitted_list := strings.Split(pathRule_json.Properties.BackendAddressPool.ID,"/")
+		pathRule_state.Backend_address_pool_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if pathRule_json.Properties.BackendHTTPSettings != nil {
+		splitted_list := strings.Split(pathRule_json.Properties.BackendHTTPSettings.ID,"/")
+		pathRule_state.Backend_http_settings_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if pathRule_json.Properties.RedirectConfiguration != nil {
+		splitted_list := strings.Split(pathRule_json.Properties.RedirectConfiguration.ID,"/")
+		pathRule_state.Redirect_configuration_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if pathRule_json.Properties.RewriteRuleSet != nil {
+		splitted_list := strings.Split(pathRule_json.Properties.RewriteRuleSet.ID,"/")
+		pathRule_state.Rewrite_rule_set_name = types.String{Value: splitted_list[len(splitted_list)-1]}
+	}
+	if pathRule_json.Properties.FirewallPolicy != nil && pathRule_json.Properties.FirewallPolicy.ID != "" {
+		pathRule_state.Firewall_policy_id = types.String{Value: pathRule_json.Properties.FirewallPolicy.ID}
+	}
+	return pathRule_state
+}
+func getURLPathMapElementKey_gw(gw ApplicationGateway, URLPathMapName string) int {
+	key := -1
+	for i := len(gw.Properties.URLPathMaps) - 1; i >= 0; i-- {
+		if gw.Properties.URLPathMaps[i].Name == URLPathMapName {
+			key = i
+		}
+	}
+	return key
+}
+func checkURLPathMapElement(gw ApplicationGateway, URLPathMapName string) bool {
+	exist := false
+	for i := len(gw.Properties.URLPathMaps) - 1; i >= 0; i-- {
+		if gw.Properties.URLPathMaps[i].Name == URLPathMapName {
+			exist = true
+		}
+	}
+	return exist
+}
+func removeURLPathMapElement(gw *ApplicationGateway, URLPathMapName string) {
+	for i := len(gw.Properties.URLPathMaps) - 1; i >= 0; i-- {
+		if gw.Properties.URLPathMaps[i].Name == URLPathMapName {
+			gw.Properties.URLPathMaps = append(gw.Properties.URLPathMaps[:i], gw.Properties.URLPathMaps[i+1:]...)
+		}
+	}
+}
+func checkURLPathMapNameInMap(URLPathMapName string, url_path_maps map[string]Url_path_map) bool {
+	for _, value := range url_path_maps {
+		if URLPathMapName == value.Name.Value {
+			return true
+		}
+	}
+	return false
+}
+func getPathRuleSortedKeys(path_rules map[string]Path_rule) []string {
+	keys := make([]string, 0, len(path_rules))
+	for key := range path_rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+func checkURLPathMapConfig(urlPathMap_plan Url_path_map, plan BindingService, gw ApplicationGateway) (string, bool) {
+	//return the error message when the targets of the url path map don't exist, and true
+	if err, fail := checkURLPathMapTargets("URL path map ("+urlPathMap_plan.Name.Value+")",
+		urlPathMap_plan.Default_backend_address_pool_name.Value, urlPathMap_plan.Default_backend_http_settings_name.Value,
+		urlPathMap_plan.Default_redirect_configuration_name.Value, urlPathMap_plan.Default_rewrite_rule_set_name.Value, plan, gw); fail {
+		return err, true
+	}
+	for _, pathRule_plan := range urlPathMap_plan.Path_rules {
+		if err, fail := checkURLPathMapTargets("path rule ("+pathRule_plan.Name.Value+") of the URL path map ("+urlPathMap_plan.Name.Value+")",
+			pathRule_plan.Backend_address_pool_name.Value, pathRule_plan.Backend_http_settings_name.Value,
+			pathRule_plan.Redirect_configuration_name.Value, pathRule_plan.Rewrite_rule_set_name.Value, plan, gw); fail {
+			return err, true
+		}
+	}
+	return "", false
+}
+func checkURLPathMapTargets(element string, backendAddressPoolName string, backendHTTPSettingsName string,
+	redirectConfigurationName string, rewriteRuleSetName string, plan BindingService, gw ApplicationGateway) (string, bool) {
+	//the target is either a redirect configuration or both a backend address pool and a backend http settings (see validateTargetsConfig).
+	//each of them has to be declared in the binding or to exist in the gw
+	if redirectConfigurationName != "" {
+		if redirectConfigurationName != plan.Redirect_configuration.Name.Value && !checkRedirectConfigurationElement(gw, redirectConfigurationName) {
+			return "The redirect configuration name ("+redirectConfigurationName+") declared in the "+element+
+				" doesn't match any existing (in the gw) nor declared (in the tf) redirect configuration. ", true
+		}
+	}else{
+		if backendAddressPoolName != plan.Backend_address_pool.Name.Value && !checkBackendAddressPoolElement(gw, backendAddressPoolName) {
+			return "The backend address pool name ("+backendAddressPoolName+") declared in the "+element+
+				" doesn't match any existing (in the gw) nor declared (in the tf) backend address pool. ", true
+		}
+		if backendHTTPSettingsName != plan.Backend_http_settings.Name.Value && !checkBackendHTTPSettingsElement(gw, backendHTTPSettingsName) {
+			return "The backend http settings name ("+backendHTTPSettingsName+") declared in the "+element+
+				" doesn't match any existing (in the gw) nor declared (in the tf) backend http settings. ", true
+		}
+	}
+	if rewriteRuleSetName != "" {
+		if err, fail := checkRewriteRuleSetReference(rewriteRuleSetName, plan, gw); fail {
+			return "In the "+element+", "+err, true
+		}
+	}
+	return "", false
+}
+func validateURLPathMapConfig(key string, urlPathMap Url_path_map, resp *tfsdk.ValidateResourceConfigResponse) {
+	//check the constraints between the attributes of the url path map and of its path rules
+	path := tftypes.NewAttributePath().WithAttributeName("url_path_maps").WithElementKeyString(key)
+	validateTargetsConfig("Url_path_map: "+urlPathMap.Name.Value, path, "default_", urlPathMap.Default_backend_address_pool_name,
+		urlPathMap.Default_backend_http_settings_name, urlPathMap.Default_redirect_configuration_name, resp)
+	if len(urlPathMap.Path_rules) == 0 {
+		resp.Diagnostics.AddAttributeError(path.WithAttributeName("path_rules"),
+			"Invalid Url_path_map: "+urlPathMap.Name.Value+", it has to contain at least one path rule.",
+			"Please, add a path rule then retry.",
+		)
+	}
+	for _, key_rule := range getPathRuleSortedKeys(urlPathMap.Path_rules) {
+		pathRule := urlPathMap.Path_rules[key_rule]
+		pathRule_path := path.WithAttributeName("path_rules").WithElementKeyString(key_rule)
+		element := "path rule ("+pathRule.Name.Value+") of the Url_path_map: "+urlPathMap.Name.Value
+		if len(pathRule.Paths) == 0 {
+			resp.Diagnostics.AddAttributeError(pathRule_path.WithAttributeName("paths"),
+				"Invalid "+element+", it has to contain at least one path.",
+				"Please, add a path then retry.",
+			)
+		}
+		for i := 0; i < len(pathRule.Paths); i++ {
+			if !pathRule.Paths[i].Unknown && !strings.HasPrefix(pathRule.Paths[i].Value, "/") {
+				resp.Diagnostics.AddAttributeError(pathRule_path.WithAttributeName("paths").WithElementKeyInt(i),
+					"Invalid "+element+", the path ("+pathRule.Paths[i].Value+") has to start with /.",
+					"Please, change the path then retry.",
+				)
+			}
+		}
+		for key_rule1, pathRule1 := range urlPathMap.Path_rules {
+			if checkStringConfigured(pathRule.Name) && pathRule.Name.Value == pathRule1.Name.Value && key_rule < key_rule1 {
+				resp.Diagnostics.AddAttributeError(pathRule_path.WithAttributeName("name"),
+					"Invalid Url_path_map: "+urlPathMap.Name.Value+", the path rules ("+key_rule+" and "+key_rule1+") have the same name: "+pathRule.Name.Value+".",
+					"Please, change the name of a path rule then retry.",
+				)
+			}
+		}
+		if checkStringConfigured(pathRule.Firewall_policy_id) && !checkFirewallPolicyID(pathRule.Firewall_policy_id.Value) {
+			resp.Diagnostics.AddAttributeError(pathRule_path.WithAttributeName("firewall_policy_id"),
+				"Invalid "+element+", the firewall policy id ("+pathRule.Firewall_policy_id.Value+") is not a valid WAF policy ID. "+
+				"It has to match the format: /subscriptions/{subscription_id}/resourceGroups/{resource_group}/providers/"+
+				"Microsoft.Network/ApplicationGatewayWebApplicationFirewallPolicies/{policy_name}",
+				"Please, change the firewall policy id then retry.",
+			)
+		}
+		validateTargetsConfig(element, pathRule_path, "", pathRule.Backend_address_pool_name, pathRule.Backend_http_settings_name,
+			pathRule.Redirect_configuration_name, resp)
+	}
+}
+func checkURLPathMapCreate(urlPathMap_plan Url_path_map, plan BindingService, gw ApplicationGateway, resp *tfsdk.CreateResourceResponse) bool {
+	if err, fail := checkURLPathMapConfig(urlPathMap_plan, plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. "+err,
+			"Please, change URL path map configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
+func checkURLPathMapUpdate(urlPathMap_plan Url_path_map, plan BindingService, gw ApplicationGateway, resp *tfsdk.UpdateResourceResponse) bool {
+	if err, fail := checkURLPathMapConfig(urlPathMap_plan, plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. "+err,
+			"Please, change URL path map configuration then retry.",
+		)
+		return true
+	}
+	return false
+}
+func getURLPathMapSortedKeys(url_path_maps map[string]Url_path_map) []string {
+	keys := make([]string, 0, len(url_path_maps))
+	for key := range url_path_maps {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -4,11 +4,27 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
+// the protocols of the listeners, the backend HTTP settings and the probes
+var protocols = []string{"Http", "Https"}
+
+// during the validation, an unknown value is neither configured nor missing, it's checked again when it becomes known
+func checkStringConfigured(value types.String) bool {
+	return !value.Unknown && !value.Null && value.Value != ""
+}
+func checkStringMissing(value types.String) bool {
+	return !value.Unknown && (value.Null || value.Value == "")
+}
+
 // BindingService
 type BindingService struct {
 	Name                 		types.String         			`tfsdk:"name"`
+	Id							types.String					`tfsdk:"id"`
+	Gateway_id					types.String					`tfsdk:"gateway_id"`
 	Agw_name             		types.String         			`tfsdk:"application_gateway_name"`
 	Agw_rg               		types.String         			`tfsdk:"application_gateway_resource_group_name"`
+	Adopt_existing				types.Bool						`tfsdk:"adopt_existing"`
+	Detach_shared_references	types.Bool						`tfsdk:"detach_shared_references"`
+	Priority_range				[]types.Int64					`tfsdk:"priority_range"`
 	Backend_address_pool		Backend_address_pool 			`tfsdk:"backend_address_pool"`
 	Backend_http_settings   	Backend_http_settings			`tfsdk:"backend_http_settings"`
 	Probe						Probe_tf						`tfsdk:"probe"`
@@ -20,4 +36,8 @@ type BindingService struct {
 	//Request_routing_rule_https	*Request_routing_rule			`tfsdk:"request_routing_rule_https"`
 	Http_listeners				map[string]Http_listener		`tfsdk:"http_listeners"`
 	Request_routing_rules 		map[string]Request_routing_rule `tfsdk:"request_routing_rules"`
+	Url_path_maps				map[string]Url_path_map			`tfsdk:"url_path_maps"`
+	Rewrite_rule_sets			map[string]Rewrite_rule_set		`tfsdk:"rewrite_rule_sets"`
+	Ssl_profiles				map[string]Ssl_profile			`tfsdk:"ssl_profiles"`
+	Trusted_client_certificates	map[string]Trusted_client_certificate	`tfsdk:"trusted_client_certificates"`
 }
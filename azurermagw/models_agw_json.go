@@ -17,8 +17,7 @@ type ApplicationGateway struct {
 	Etag     string `json:"etag"`
 	Type     string `json:"type"`
 	Location string `json:"location"`
-	Tags     struct {
-	} `json:"tags"`
+	Tags     map[string]string `json:"tags,omitempty"`
 	Identity *struct { //Identity `json:"identity,omitempty"`
 		Type                   string      `json:"type,omitempty"`
 		UserAssignedIdentities interface{} `json:"userAssignedIdentities,omitempty"`
@@ -171,38 +170,7 @@ type ApplicationGateway struct {
 		Probes []Probe_json `json:"probes"`
 		RedirectConfigurations []RedirectConfiguration `json:"redirectConfigurations,omitempty"`
 		RequestRoutingRules []RequestRoutingRule `json:"requestRoutingRules,omitempty"`
-		RewriteRuleSets []struct {
-			Name       string `json:"name"`
-			ID         string `json:"id"`
-			Etag       string `json:"etag"`
-			Properties struct {
-				ProvisioningState string `json:"provisioningState"`
-				RewriteRules      []struct {
-					RuleSequence int           `json:"ruleSequence"`
-					Conditions   []interface{} `json:"conditions"`
-					Name         string        `json:"name"`
-					ActionSet    struct {
-						RequestHeaderConfigurations []struct {
-							HeaderName  string `json:"headerName"`
-							HeaderValue string `json:"headerValue"`
-						} `json:"requestHeaderConfigurations"`
-						ResponseHeaderConfigurations []struct {
-							HeaderName  string `json:"headerName"`
-							HeaderValue string `json:"headerValue"`
-						} `json:"responseHeaderConfigurations"`
-						URLConfiguration struct {
-							ModifiedPath        string `json:"modifiedPath"`
-							ModifiedQueryString string `json:"modifiedQueryString"`
-							Reroute             bool   `json:"reroute"`
-						} `json:"urlConfiguration"`
-					} `json:"actionSet"`
-				} `json:"rewriteRules"`
-				RequestRoutingRules []struct {
-					ID string `json:"id"`
-				} `json:"requestRoutingRules"`
-			} `json:"properties"`
-			Type string `json:"type"`
-		} `json:"rewriteRuleSets"`
+		RewriteRuleSets []RewriteRuleSet `json:"rewriteRuleSets,omitempty"`
 		RoutingRules []struct {
 			ID         string `json:"id"`
 			Name       string `json:"name"`
@@ -232,86 +200,10 @@ type ApplicationGateway struct {
 			PolicyName           string   `json:"policyName"`
 			PolicyType           string   `json:"policyType"`
 		} `json:"sslPolicy"`
-		SslProfiles []struct {
-			ID         string `json:"id"`
-			Name       string `json:"name"`
-			Properties struct {
-				ClientAuthConfiguration struct {
-					VerifyClientCertIssuerDN bool `json:"verifyClientCertIssuerDN"`
-				} `json:"clientAuthConfiguration"`
-				SslPolicy struct {
-					CipherSuites         []string `json:"cipherSuites"`
-					DisabledSslProtocols []string `json:"disabledSslProtocols"`
-					MinProtocolVersion   string   `json:"minProtocolVersion"`
-					PolicyName           string   `json:"policyName"`
-					PolicyType           string   `json:"policyType"`
-				} `json:"sslPolicy"`
-				TrustedClientCertificates []struct {
-					ID string `json:"id"`
-				} `json:"trustedClientCertificates"`
-			} `json:"properties"`
-		} `json:"sslProfiles"`
-		TrustedClientCertificates []struct {
-			ID         string `json:"id"`
-			Name       string `json:"name"`
-			Properties struct {
-				Data string `json:"data"`
-			} `json:"properties"`
-		} `json:"trustedClientCertificates"`
-		TrustedRootCertificates []struct {
-			ID         string `json:"id"`
-			Name       string `json:"name"`
-			Properties struct {
-				Data             string `json:"data"`
-				KeyVaultSecretID string `json:"keyVaultSecretId"`
-			} `json:"properties"`
-		} `json:"trustedRootCertificates"`
-		URLPathMaps []struct {
-			ID         string `json:"id"`
-			Name       string `json:"name"`
-			Properties struct {
-				DefaultBackendAddressPool struct {
-					ID string `json:"id"`
-				} `json:"defaultBackendAddressPool"`
-				DefaultBackendHTTPSettings struct {
-					ID string `json:"id"`
-				} `json:"defaultBackendHttpSettings"`
-				DefaultLoadDistributionPolicy struct {
-					ID string `json:"id"`
-				} `json:"defaultLoadDistributionPolicy"`
-				DefaultRedirectConfiguration struct {
-					ID string `json:"id"`
-				} `json:"defaultRedirectConfiguration"`
-				DefaultRewriteRuleSet struct {
-					ID string `json:"id"`
-				} `json:"defaultRewriteRuleSet"`
-				PathRules []struct {
-					ID         string `json:"id"`
-					Name       string `json:"name"`
-					Properties struct {
-						BackendAddressPool struct {
-							ID string `json:"id"`
-						} `json:"backendAddressPool"`
-						BackendHTTPSettings struct {
-							ID string `json:"id"`
-						} `json:"backendHttpSettings"`
-						FirewallPolicy *struct {
-							ID string `json:"id"`
-						} `json:"firewallPolicy"`
-						LoadDistributionPolicy struct {
-							ID string `json:"id"`
-						} `json:"loadDistributionPolicy"`
-						Paths                 []string `json:"paths"`
-						RedirectConfiguration struct {
-							ID string `json:"id"`
-						} `json:"redirectConfiguration"`
-						RewriteRuleSet struct {
-							ID string `json:"id"`
-						} `json:"rewriteRuleSet"`
-					} `json:"properties"`
-				} `json:"pathRules"`
-			} `json:"properties"`
-		} `json:"urlPathMaps"`
+		SslProfiles []SslProfile `json:"sslProfiles,omitempty"`
+		TrustedClientCertificates []TrustedClientCertificate `json:"trustedClientCertificates,omitempty"`
+		TrustedRootCertificates []TrustedRootCertificate `json:"trustedRootCertificates,omitempty"`
+		URLPathMaps []URLPathMap `json:"urlPathMaps,omitempty"`
 		WebApplicationFirewallConfiguration *struct {
 			Enabled            bool   `json:"enabled"`
 			MaxRequestBodySize int    `json:"maxRequestBodySize,omitempty"`
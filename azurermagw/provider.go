@@ -195,6 +195,7 @@ func (p *provider) GetResources(_ context.Context) (map[string]tfsdk.ResourceTyp
 	return map[string]tfsdk.ResourceType{
 		//"hashicups_order": resourceOrderType{},
 		"azurermagw_binding_service": resourceBindingServiceType{},
+		"azurermagw_trusted_root_certificate": resourceTrustedRootCertificateType{},
 	}, nil
 }
 
@@ -3,21 +3,25 @@ package azurermagw
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"terraform-provider-azurermagw/azurermagw/validators"
 	//"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
@@ -30,22 +34,74 @@ type resourceBindingService struct {
 // Order Resource schema
 func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
 	return tfsdk.Schema{
+		//version 1: the priority of the request routing rules is stored as a number instead of a string
+		Version: 1,
 		Attributes: map[string]tfsdk.Attribute{
 			"name": { // Containe the name of the Binding resource
 				Type:     types.StringType,
 				Required: true,
 				//Description: "This is a description message",
-				MarkdownDescription: "The name of the binding service that bind an backend application (VM, web app, container web app, etc.) to the azure application gateway.",
+				MarkdownDescription: "The name of the binding service that bind an backend application (VM, web app, container web app, etc.) to the azure application gateway. "+
+				"It has to be unique within the application gateway: it identifies the elements of the binding in the tags of the gateway. "+
+				"As a part of a tag name, it can't contain the characters `<>%&\\?/` and its length is limited to "+strconv.Itoa(maxBindingServiceNameLength)+".",
+				Validators: []tfsdk.AttributeValidator{
+					validators.StringLenBetween(1, maxBindingServiceNameLength),
+					validators.StringNotContainAny(invalidTagNameCharacters),
+				},
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				MarkdownDescription: "The ID of the binding service: the ID of the application gateway followed by `/bindingServices/` and the name of the binding. "+
+				"It is known during the plan when the application gateway already exists.",
+			},
+			"gateway_id": {
+				Type:     types.StringType,
+				Computed: true,
+				MarkdownDescription: "The ID of the application gateway. It is known during the plan when the application gateway already exists.",
 			},
 			"application_gateway_name": {
 				Type:     types.StringType,
 				Required: true,
-				MarkdownDescription: "The name of the application gateway to which the backend application will be binded.",
+				PlanModifiers: tfsdk.AttributePlanModifiers{tfsdk.RequiresReplace()},
+				MarkdownDescription: "The name of the application gateway to which the backend application will be binded. "+
+				"Changing it forces a new resource to be created: the binding is removed from the old gateway then created in the new one.",
 			},
 			"application_gateway_resource_group_name": {
 				Type:     types.StringType,
 				Required: true,
-				MarkdownDescription: "The name of the resource group where the application gateway is deployed.",
+				PlanModifiers: tfsdk.AttributePlanModifiers{tfsdk.RequiresReplace()},
+				MarkdownDescription: "The name of the resource group where the application gateway is deployed. "+
+				"Changing it forces a new resource to be created.",
+			},
+			"adopt_existing": {
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{boolDefault(false)},
+				MarkdownDescription: "Whether the elements already existing in the application gateway with the name of an element of the binding are "+
+				"adopted: they are replaced by the configuration of the binding and taken under its management, instead of causing an error. "+
+				"The adopted elements are listed as warnings during the plan. The elements of another binding are never adopted. Defaults to `false`.",
+			},
+			"detach_shared_references": {
+				Type:     types.BoolType,
+				Optional: true,
+				Computed: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{boolDefault(false)},
+				MarkdownDescription: "Whether the optional references held by the other elements of the application gateway to the elements removed "+
+				"(or renamed) by the binding are detached: the probe of a backend http settings, the SSL profile of a listener, the rewrite rule set "+
+				"of a rule or a path rule and the trusted client certificates of an SSL profile. Otherwise, the removal fails and lists the references. "+
+				"The required references (for example the listener of a rule) are never detached. The value of the state is used by the destroy, "+
+				"so it has to be applied before. Defaults to `false`.",
+			},
+			"priority_range": {
+				Type: types.ListType{
+					ElemType: types.Int64Type,
+				},
+				Optional: true,
+				MarkdownDescription: "The band of priorities (first and last values, for example `[1000, 1999]`) in which the provider allocates the priority "+
+				"of the request routing rules that don't declare one. Each rule gets the lowest free priority of the band, in the order of the rule keys. "+
+				"Defaults to `[1, 300]`.",
 			},
 			"backend_address_pool": {
 				Required: true,
@@ -59,7 +115,7 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `backend_address_pool`.",
+						MarkdownDescription: "The ID of the `backend_address_pool`. It is known during the plan when the application gateway already exists.",
 					},
 					"fqdns": {
 						Type: types.ListType{
@@ -89,7 +145,7 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `backend_http_settings`.",
+						MarkdownDescription: "The ID of the `backend_http_settings`. It is known during the plan when the application gateway already exists.",
 					},
 					//the affinity has a default value if it's not provided: "ApplicationGatewayAffinity"
 					"affinity_cookie_name": {
@@ -103,6 +159,7 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 						Type:     types.StringType,
 						Required: true,
 						MarkdownDescription: "Is Cookie-Based Affinity enabled? Possible values are `Enabled` and `Disabled`.",
+						Validators: []tfsdk.AttributeValidator{validators.StringInSlice(cookieBasedAffinities, false)},
 					},
 					"pick_host_name_from_backend_address": {
 						Type:     types.BoolType,
@@ -120,17 +177,58 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 						Type:     types.StringType,
 						Required: true,
 						MarkdownDescription: "The Protocol which should be used. Possible values are `Http` and `Https`.",
+						Validators: []tfsdk.AttributeValidator{validators.StringInSlice(protocols, false)},
 					},
 					"request_timeout": {
 						Type:     types.Int64Type,
 						Required: true,
 						MarkdownDescription: "The request timeout in seconds, which must be between 1 and 86400 seconds.",
+						Validators: []tfsdk.AttributeValidator{validators.Int64Between(minRequestTimeout, maxRequestTimeout)},
 					},
 					"probe_name": {
 						Type:     types.StringType,
 						Optional: true,
 						MarkdownDescription: "The name of an associated HTTP Probe.",
 					},
+					"host_name": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "Host header to be sent to the backend servers. Cannot be set if `pick_host_name_from_backend_address` is set to `true`.",
+					},
+					"path": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The Path which should be used as a prefix for all HTTP requests. It has to start with `/`.",
+					},
+					"connection_draining": {
+						Optional: true,
+						MarkdownDescription: "One `connection_draining` block as defined below.",
+						Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+							"enabled": {
+								Type:     types.BoolType,
+								Required: true,
+								MarkdownDescription: "If connection draining is enabled or not.",
+							},
+							"drain_timeout_sec": {
+								Type:     types.Int64Type,
+								Required: true,
+								MarkdownDescription: "The number of seconds connection draining is active. Acceptable values are from `1` second to `3600` seconds.",
+								Validators: []tfsdk.AttributeValidator{validators.Int64Between(minDrainTimeoutSec, maxDrainTimeoutSec)},
+							},
+						}),
+					},
+					"trusted_root_certificate_names": {
+						Type:     types.ListType{ElemType: types.StringType},
+						Optional: true,
+						MarkdownDescription: "A list of trusted root certificate names used to verify the backend servers. Only valid for the `Https` protocol and the v2 SKUs. "+
+						"Each name has to match an existing trusted root certificate of the gateway.",
+					},
+					"authentication_certificate_names": {
+						Type:     types.ListType{ElemType: types.StringType},
+						Optional: true,
+						MarkdownDescription: "A list of authentication certificate names used to authenticate the backend servers. Only valid for the `Https` protocol and the v1 SKUs. "+
+						"Each name has to match an existing authentication certificate of the gateway.",
+					},
 				}),
 			},
 			"probe": {
@@ -145,17 +243,19 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `probe`.",
+						MarkdownDescription: "The ID of the `probe`. It is known during the plan when the application gateway already exists.",
 					},
 					"interval": {
 						Type:     types.Int64Type,
 						Required: true,
 						MarkdownDescription: "The Interval between two consecutive probes in seconds. Possible values range from 1 second to a maximum of 86,400 seconds.",
+						Validators: []tfsdk.AttributeValidator{validators.Int64Between(minProbeInterval, maxProbeInterval)},
 					},
 					"protocol": {
 						Type:     types.StringType,
 						Required: true,
 						MarkdownDescription: "The Protocol used for this Probe. Possible values are `Http` and `Https`.",
+						Validators: []tfsdk.AttributeValidator{validators.StringInSlice(protocols, false)},
 					},
 					"path": {
 						Type:     types.StringType,
@@ -173,11 +273,13 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 						Type:     types.Int64Type,
 						Required: true,
 						MarkdownDescription: "The Timeout used for this Probe, which indicates when a probe becomes unhealthy. Possible values range from 1 second to a maximum of 86,400 seconds.",
+						Validators: []tfsdk.AttributeValidator{validators.Int64Between(minProbeTimeout, maxProbeTimeout)},
 					},
 					"unhealthy_threshold": {
 						Type:     types.Int64Type,
 						Required: true,
 						MarkdownDescription: "The Unhealthy Threshold for this Probe, which indicates the amount of retries which should be attempted before a node is deemed unhealthy. Possible values are from 1 to 20.",
+						Validators: []tfsdk.AttributeValidator{validators.Int64Between(minProbeUnhealthyThreshold, maxProbeUnhealthyThreshold)},
 					},	
 					"minimum_servers": {
 						Type:     types.Int64Type,
@@ -186,21 +288,35 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 						PlanModifiers: tfsdk.AttributePlanModifiers{intDefault(0)},
 						MarkdownDescription: "The minimum number of servers that are always marked as healthy. Defaults to `0`.",
 					},
+					"host": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The Hostname used for this Probe. Cannot be set if `pick_host_name_from_backend_http_settings` is set to `true`.",
+					},
+					"port": {
+						Type:     types.Int64Type,
+						Optional: true,
+						MarkdownDescription: "Custom port which will be used for probing the backend servers, from `1` to `65535`. Only valid for v2 SKUs. "+
+						"When omitted, the port of the backend HTTP settings is used.",
+						Validators: []tfsdk.AttributeValidator{validators.Int64Between(minProbePort, maxProbePort)},
+					},
 					"match": {
-						Required: true,
-						MarkdownDescription: "A `match` block as defined above.",
+						Optional: true,
+						MarkdownDescription: "A `match` block as defined above. When omitted, Azure considers the status codes `200-399` as healthy.",
 						Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
 							"body": {
 								Type:     types.StringType,
-								Required: true,
-								MarkdownDescription: "A snippet from the Response Body which must be present in the Response.",
+								Optional: true,
+								Computed: true,
+								PlanModifiers: tfsdk.AttributePlanModifiers{stringDefault("")},
+								MarkdownDescription: "A snippet from the Response Body which must be present in the Response. Defaults to an empty string.",
 							},
 							"status_code": {
 								Type: types.ListType{
 									ElemType: types.StringType,
 								},
 								Required: true,
-								MarkdownDescription: "A list of allowed status codes for this Health Probe.",
+								MarkdownDescription: "A list of allowed status codes for this Health Probe. Each one is a single code (`200`) or a range of codes (`200-399`).",
 							},
 						}),
 					},
@@ -218,32 +334,82 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `ssl_certificate`.",
+						MarkdownDescription: "The ID of the `ssl_certificate`. It is known during the plan when the application gateway already exists.",
 					},
 					"key_vault_secret_id": {
 						Type:     types.StringType,
 						Optional: true,
 						MarkdownDescription: "Secret Id of (base-64 encoded unencrypted pfx) `Secret` or `Certificate` object stored in Azure KeyVault. "+
 						"You need to enable soft delete for keyvault to use this feature. Required if `data` is not set. "+
-						"TLS termination with Key Vault certificates is limited to the v2 SKUs.",
+						"TLS termination with Key Vault certificates is limited to the v2 SKUs. "+
+						"A versionless secret ID is rotated automatically by the gateway, a versioned one has to be updated to use a new certificate. "+
+						"The gateway reads the secret with its managed identity.",
+					},
+					"key_vault_secret_version": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The version configured in `key_vault_secret_id`. It is null for a versionless secret ID: the gateway rotates the certificate "+
+						"automatically and this attribute doesn't tell which version is in use, the `thumbprint` identifies the certificate currently "+
+						"used by the gateway.",
 					},
 					"data": {
 						Type:     types.StringType,
 						Optional: true,
 						Sensitive: true,
-						MarkdownDescription: "PFX certificate. Required if `key_vault_secret_id` is not set. Important: PFX certificate is not supported in the current provider version.",
+						MarkdownDescription: "The base64 encoded content of the PFX file (for example with `filebase64()`). Required if `key_vault_secret_id` is not set. "+
+						"The PFX is decoded with the `password` during the plan: it has to contain a private key and its certificate. "+
+						"It is never read back from the gateway, a change of the certificate outside Terraform is detected with its public data.",
 					},
 					"password": {
 						Type:     types.StringType,
 						Optional: true,
 						Sensitive: true,
 						MarkdownDescription: "Password for the pfx file specified in data. Required if `data` is set.",
-					},/*
+					},
+					"expiry_warning_days": {
+						Type:     types.Int64Type,
+						Optional: true,
+						Computed: true,
+						PlanModifiers: tfsdk.AttributePlanModifiers{intDefault(defaultExpiryWarningDays)},
+						MarkdownDescription: "A warning is raised during the plan when the certificate expires within this number of days. "+
+						"`0` disables the warning. Defaults to `30`.",
+					},
 					"public_cert_data": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "",
-					},*/
+						MarkdownDescription: "The base64 encoded public certificate data (PKCS#7) returned by the gateway.",
+					},
+					"thumbprint": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The SHA-1 thumbprint of the certificate currently used by the gateway, read from its public certificate data. "+
+						"With a versionless Key Vault secret ID, it changes when the gateway rotates the certificate.",
+					},
+					"subject": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The subject of the certificate.",
+					},
+					"issuer": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The issuer of the certificate.",
+					},
+					"not_before": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The start of the validity period of the certificate (RFC3339).",
+					},
+					"not_after": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The expiry date of the certificate (RFC3339).",
+					},
+					"dns_names": {
+						Type:     types.ListType{ElemType: types.StringType},
+						Computed: true,
+						MarkdownDescription: "The DNS names of the subject alternative name extension of the certificate.",
+					},
 				}),
 			},
 			"redirect_configuration": {
@@ -258,12 +424,13 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `redirect_configuration`.",
+						MarkdownDescription: "The ID of the `redirect_configuration`. It is known during the plan when the application gateway already exists.",
 					},
 					"redirect_type": {
 						Type:     types.StringType,
 						Required: true,
 						MarkdownDescription: "The type of redirect. Possible values are `Permanent`, `Temporary`, `Found` and `SeeOther`.",
+						Validators: []tfsdk.AttributeValidator{validators.StringInSlice(redirectTypes, false)},
 					},
 					"target_listener_name": {
 						Type:     types.StringType,
@@ -303,18 +470,21 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `request_routing_rule`.",
+						MarkdownDescription: "The ID of the `request_routing_rule`. It is known during the plan when the application gateway already exists.",
 					},
 					"rule_type": {
 						Type:     types.StringType,
 						Required: true,
 						MarkdownDescription: "The Type of Routing that should be used for this Rule. Possible values are `Basic` and `PathBasedRouting`.",
+						Validators: []tfsdk.AttributeValidator{validators.StringInSlice(requestRoutingRuleTypes, false)},
 					},
 					"priority": {
-						Type:     types.StringType,
+						Type:     types.Int64Type,
+						Optional: true,
 						Computed: true,
-						MarkdownDescription: "Rule evaluation order can be dictated by specifying an integer value from `1` to `20000` with `1` being the highest priority and `20000` being the lowest priority."+
-						"For this version, the priority is computed by the provider (between 1 and 300) after getting the list of used values from the gateway.",
+						MarkdownDescription: "Rule evaluation order can be dictated by specifying an integer value from `1` to `20000` with `1` being the highest priority and `20000` being the lowest priority. "+
+						"The priority must not be already used by another request routing rule of the gateway. "+
+						"If it is not set, the priority is allocated by the provider: the lowest free value of `priority_range` after getting the list of used values from the gateway.",
 					},
 					"http_listener_name": {
 						Type:     types.StringType,
@@ -343,12 +513,315 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"rewrite_rule_set_name": {
 						Type:     types.StringType,
 						Optional: true,
-						MarkdownDescription: "The Name of the Rewrite Rule Set which should be used for this Routing Rule. Only valid for v2 SKUs. Not supported in this version",
+						MarkdownDescription: "The Name of the Rewrite Rule Set which should be used for this Routing Rule. Only valid for v2 SKUs. "+
+						"It has to match a Rewrite Rule Set name declared in the binding service resource or an existing one in the gateway.",
 					},
 					"url_path_map_name": {
 						Type:     types.StringType,
 						Optional: true,
-						MarkdownDescription: "The Name of the URL Path Map which should be associated with this Routing Rule. Not supported in this version",
+						MarkdownDescription: "The Name of the URL Path Map which should be associated with this Routing Rule. Required if `rule_type` is `PathBasedRouting`, cannot be set otherwise. "+
+						"It has to match a URL Path Map name declared in the binding service resource or an existing one in the gateway.",
+					},
+				},tfsdk.MapNestedAttributesOptions{}),
+			},
+			"url_path_maps": {
+				Optional: true,
+				MarkdownDescription: "The url path maps block has to be defined as a map with a key name for each `url_path_map`. They are used by the request routing rules of type `PathBasedRouting`. See Example usage for details.",
+				Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						Type:     types.StringType,
+						Required: true,
+						MarkdownDescription: "The Name of the URL Path Map.",
+					},
+					"id": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The ID of the `url_path_map`. It is known during the plan when the application gateway already exists.",
+					},
+					"default_backend_address_pool_name": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The Name of the Default Backend Address Pool which should be used for this URL Path Map. Cannot be set if `default_redirect_configuration_name` is set. "+
+						"It has to match the Backend Address Pool name declared in the binding service resource or an existing one in the gateway.",
+					},
+					"default_backend_http_settings_name": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The Name of the Default Backend HTTP Settings Collection which should be used for this URL Path Map. Cannot be set if `default_redirect_configuration_name` is set. "+
+						"It has to match the Backend HTTP Settings name declared in the binding service resource or an existing one in the gateway.",
+					},
+					"default_redirect_configuration_name": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The Name of the Default Redirect Configuration which should be used for this URL Path Map. "+
+						"Cannot be set if either `default_backend_address_pool_name` or `default_backend_http_settings_name` is set. "+
+						"It has to match the Redirect Configuration name declared in the binding service resource or an existing one in the gateway.",
+					},
+					"default_rewrite_rule_set_name": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The Name of the Default Rewrite Rule Set which should be used for this URL Path Map. Only valid for v2 SKUs. "+
+						"It has to match a Rewrite Rule Set name declared in the binding service resource or an existing one in the gateway.",
+					},
+					"path_rules": {
+						Required: true,
+						MarkdownDescription: "At least one block has to be defined. The path rules block has to be defined as a map with a key name for each `path_rule`.",
+						Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+							"name": {
+								Type:     types.StringType,
+								Required: true,
+								MarkdownDescription: "The Name of the Path Rule.",
+							},
+							"id": {
+								Type:     types.StringType,
+								Computed: true,
+								MarkdownDescription: "The ID of the `path_rule`. It is known during the plan when the application gateway already exists.",
+							},
+							"paths": {
+								Type: types.ListType{
+									ElemType: types.StringType,
+								},
+								Required: true,
+								MarkdownDescription: "A list of Paths used in this Path Rule. Each path has to start with `/`.",
+							},
+							"backend_address_pool_name": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The Name of the Backend Address Pool to use for this Path Rule. Cannot be set if `redirect_configuration_name` is set. "+
+								"It has to match the Backend Address Pool name declared in the binding service resource or an existing one in the gateway.",
+							},
+							"backend_http_settings_name": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The Name of the Backend HTTP Settings Collection to use for this Path Rule. Cannot be set if `redirect_configuration_name` is set. "+
+								"It has to match the Backend HTTP Settings name declared in the binding service resource or an existing one in the gateway.",
+							},
+							"redirect_configuration_name": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The Name of the Redirect Configuration to use for this Path Rule. "+
+								"Cannot be set if either `backend_address_pool_name` or `backend_http_settings_name` is set. "+
+								"It has to match the Redirect Configuration name declared in the binding service resource or an existing one in the gateway.",
+							},
+							"firewall_policy_id": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The ID of the Web Application Firewall Policy which should be used for this Path Rule. "+
+								"It has to be the ID of an `ApplicationGatewayWebApplicationFirewallPolicies` resource. Only effective with the `WAF_v2` SKU.",
+							},
+							"rewrite_rule_set_name": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The Name of the Rewrite Rule Set which should be used for this Path Rule. Only valid for v2 SKUs. "+
+								"It has to match a Rewrite Rule Set name declared in the binding service resource or an existing one in the gateway.",
+							},
+						},tfsdk.MapNestedAttributesOptions{}),
+					},
+				},tfsdk.MapNestedAttributesOptions{}),
+			},
+			"rewrite_rule_sets": {
+				Optional: true,
+				MarkdownDescription: "The rewrite rule sets block has to be defined as a map with a key name for each `rewrite_rule_set`. "+
+				"They can be used by the request routing rules and the path rules. Only valid for v2 SKUs. See Example usage for details.",
+				Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						Type:     types.StringType,
+						Required: true,
+						MarkdownDescription: "Unique name of the rewrite rule set block.",
+					},
+					"id": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The ID of the `rewrite_rule_set`. It is known during the plan when the application gateway already exists.",
+					},
+					"rewrite_rules": {
+						Required: true,
+						MarkdownDescription: "At least one block has to be defined. The rewrite rules block has to be defined as a map with a key name for each `rewrite_rule`.",
+						Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+							"name": {
+								Type:     types.StringType,
+								Required: true,
+								MarkdownDescription: "Unique name of the rewrite rule block.",
+							},
+							"rule_sequence": {
+								Type:     types.Int64Type,
+								Required: true,
+								MarkdownDescription: "Rule sequence of the rewrite rule that determines the order of execution in a set. It has to be between `1` and `1000`.",
+								Validators: []tfsdk.AttributeValidator{validators.Int64Between(minRewriteRuleSequence, maxRewriteRuleSequence)},
+							},
+							"conditions": {
+								Optional: true,
+								MarkdownDescription: "One or more `condition` blocks as defined below. All the conditions have to be satisfied to apply the actions.",
+								Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+									"variable": {
+										Type:     types.StringType,
+										Required: true,
+										MarkdownDescription: "The variable of the condition: a server variable (`var_*`), a request header (`http_req_*`) or a response header (`http_resp_*`).",
+									},
+									"pattern": {
+										Type:     types.StringType,
+										Required: true,
+										MarkdownDescription: "The pattern, either fixed string or regular expression, that evaluates the truthfulness of the condition.",
+									},
+									"ignore_case": {
+										Type:     types.BoolType,
+										Optional: true,
+										Computed: true,
+										PlanModifiers: tfsdk.AttributePlanModifiers{boolDefault(false)},
+										MarkdownDescription: "Perform a case in-sensitive comparison. Defaults to `false`.",
+									},
+									"negate": {
+										Type:     types.BoolType,
+										Optional: true,
+										Computed: true,
+										PlanModifiers: tfsdk.AttributePlanModifiers{boolDefault(false)},
+										MarkdownDescription: "Negate the result of the condition evaluation. Defaults to `false`.",
+									},
+								},tfsdk.ListNestedAttributesOptions{}),
+							},
+							"request_header_configurations": {
+								Optional: true,
+								MarkdownDescription: "One or more `request_header_configuration` blocks as defined below.",
+								Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+										"header_name": {
+											Type:     types.StringType,
+											Required: true,
+											MarkdownDescription: "Header name of the header configuration.",
+										},
+										"header_value": {
+											Type:     types.StringType,
+											Optional: true,
+											MarkdownDescription: "Header value of the header configuration. To delete the header, this value has to be omitted.",
+										},
+									},tfsdk.ListNestedAttributesOptions{}),
+							},
+							"response_header_configurations": {
+								Optional: true,
+								MarkdownDescription: "One or more `response_header_configuration` blocks as defined below.",
+								Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+										"header_name": {
+											Type:     types.StringType,
+											Required: true,
+											MarkdownDescription: "Header name of the header configuration.",
+										},
+										"header_value": {
+											Type:     types.StringType,
+											Optional: true,
+											MarkdownDescription: "Header value of the header configuration. To delete the header, this value has to be omitted.",
+										},
+									},tfsdk.ListNestedAttributesOptions{}),
+							},
+							"url": {
+								Optional: true,
+								MarkdownDescription: "One `url` block as defined below.",
+								Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+									"path": {
+										Type:     types.StringType,
+										Optional: true,
+										MarkdownDescription: "The URL path to rewrite.",
+									},
+									"query_string": {
+										Type:     types.StringType,
+										Optional: true,
+										MarkdownDescription: "The query string to rewrite.",
+									},
+									"reroute": {
+										Type:     types.BoolType,
+										Optional: true,
+										Computed: true,
+										PlanModifiers: tfsdk.AttributePlanModifiers{boolDefault(false)},
+										MarkdownDescription: "Whether the URL path map should be reevaluated after this rewrite has been applied. Defaults to `false`.",
+									},
+								}),
+							},
+						},tfsdk.MapNestedAttributesOptions{}),
+					},
+				},tfsdk.MapNestedAttributesOptions{}),
+			},
+			"ssl_profiles": {
+				Optional: true,
+				MarkdownDescription: "The SSL profiles block has to be defined as a map with a key name for each `ssl_profile`. "+
+				"They can be attached to the HTTPS listeners to set a listener-level TLS policy. Only valid for v2 SKUs. See Example usage for details.",
+				Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						Type:     types.StringType,
+						Required: true,
+						MarkdownDescription: "Unique name of the SSL profile block.",
+					},
+					"id": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The ID of the `ssl_profile`. It is known during the plan when the application gateway already exists.",
+					},
+					"ssl_policy": {
+						Optional: true,
+						MarkdownDescription: "One `ssl_policy` block as defined below. When omitted, the listener uses the TLS policy of the gateway.",
+						Attributes: tfsdk.SingleNestedAttributes(map[string]tfsdk.Attribute{
+							"policy_type": {
+								Type:     types.StringType,
+								Required: true,
+								MarkdownDescription: "The type of the SSL policy. Possible values are `Predefined`, `Custom` and `CustomV2`.",
+								Validators: []tfsdk.AttributeValidator{validators.StringInSlice(sslPolicyTypes, false)},
+							},
+							"policy_name": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The name of the predefined SSL policy. Required when `policy_type` is `Predefined`, and cannot be set otherwise. "+
+								"Possible values are `AppGwSslPolicy20150501`, `AppGwSslPolicy20170401`, `AppGwSslPolicy20170401S`, `AppGwSslPolicy20220101` and `AppGwSslPolicy20220101S`.",
+								Validators: []tfsdk.AttributeValidator{validators.StringInSlice(sslPolicyNames, false)},
+							},
+							"min_protocol_version": {
+								Type:     types.StringType,
+								Optional: true,
+								MarkdownDescription: "The minimal TLS version. Required when `policy_type` is `Custom` or `CustomV2`, and cannot be set otherwise. "+
+								"Possible values are `TLSv1_0`, `TLSv1_1`, `TLSv1_2` and `TLSv1_3` (only with `CustomV2`).",
+								Validators: []tfsdk.AttributeValidator{validators.StringInSlice(sslProtocolVersions, false)},
+							},
+							"cipher_suites": {
+								Type:     types.ListType{ElemType: types.StringType},
+								Optional: true,
+								MarkdownDescription: "A list of accepted cipher suites. Required when `policy_type` is `Custom` or `CustomV2`, "+
+								"unless `min_protocol_version` is `TLSv1_3`, and cannot be set for a `Predefined` policy.",
+							},
+						}),
+					},
+					"trusted_client_certificate_names": {
+						Type:     types.ListType{ElemType: types.StringType},
+						Optional: true,
+						MarkdownDescription: "A list of trusted client certificate names used to verify the client certificates (mutual TLS). "+
+						"Setting it enables the client authentication on the listeners using this profile. "+
+						"Each name has to match a trusted client certificate name declared in the binding service resource or an existing one in the gateway.",
+					},
+					"verify_client_cert_issuer_dn": {
+						Type:     types.BoolType,
+						Optional: true,
+						Computed: true,
+						PlanModifiers: tfsdk.AttributePlanModifiers{boolDefault(false)},
+						MarkdownDescription: "Should the issuer DN of the client certificate be verified against the trusted client certificates? "+
+						"Can only be enabled with `trusted_client_certificate_names`. Defaults to `false`.",
+					},
+				},tfsdk.MapNestedAttributesOptions{}),
+			},
+			"trusted_client_certificates": {
+				Optional: true,
+				MarkdownDescription: "The trusted client certificates block has to be defined as a map with a key name for each `trusted_client_certificate`. "+
+				"They are the CA certificates used by the SSL profiles to verify the client certificates. Only valid for v2 SKUs. See Example usage for details.",
+				Attributes: tfsdk.MapNestedAttributes(map[string]tfsdk.Attribute{
+					"name": {
+						Type:     types.StringType,
+						Required: true,
+						MarkdownDescription: "Unique name of the trusted client certificate block.",
+					},
+					"id": {
+						Type:     types.StringType,
+						Computed: true,
+						MarkdownDescription: "The ID of the `trusted_client_certificate`. It is known during the plan when the application gateway already exists.",
+					},
+					"data": {
+						Type:     types.StringType,
+						Required: true,
+						MarkdownDescription: "The PEM encoded CA certificate(s), or their base64 encoding. Only CA certificates are accepted. "+
+						"The data is parsed by the provider before updating the gateway.",
 					},
 				},tfsdk.MapNestedAttributesOptions{}),
 			},
@@ -364,17 +837,17 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 					"id": {
 						Type:     types.StringType,
 						Computed: true,
-						MarkdownDescription: "The ID of the `http_listener`.",
+						MarkdownDescription: "The ID of the `http_listener`. It is known during the plan when the application gateway already exists.",
 					},
 					"frontend_ip_configuration_name": {
 						Type:     types.StringType,
 						Required: true,
-						MarkdownDescription: "The Name of the Frontend IP Configuration used for this HTTP Listener.",
+						MarkdownDescription: "The Name of the Frontend IP Configuration used for this HTTP Listener. It has to exist in the application gateway, it is checked during the plan.",
 					},
 					"frontend_port_name": {
 						Type:     types.StringType,
 						Required: true,
-						MarkdownDescription: "The Name of the Frontend Port use for this HTTP Listener.",
+						MarkdownDescription: "The Name of the Frontend Port use for this HTTP Listener. It has to exist in the application gateway, it is checked during the plan.",
 					},
 					"require_sni": {
 						Type:     types.BoolType,
@@ -387,6 +860,7 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 						Type:     types.StringType,
 						Required: true,
 						MarkdownDescription: "The Protocol to use for this HTTP Listener. Possible values are `Http` and `Https`.",
+						Validators: []tfsdk.AttributeValidator{validators.StringInSlice(protocols, false)},
 					},
 					"host_name": {
 						Type:     types.StringType,
@@ -407,6 +881,35 @@ func (r resourceBindingServiceType) GetSchema(_ context.Context) (tfsdk.Schema,
 						MarkdownDescription: "The name of the associated SSL Certificate which should be used for this HTTP Listener."+
 						"It has to match a Ssl certificate name declared in the binding service resource.",
 					},
+					"ssl_profile_name": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The name of the associated SSL Profile which should be used for this HTTP Listener. Only valid for `Https` listeners. "+
+						"It has to match a SSL profile name declared in the binding service resource or an existing one in the gateway.",
+					},
+					"firewall_policy_id": {
+						Type:     types.StringType,
+						Optional: true,
+						MarkdownDescription: "The ID of the Web Application Firewall Policy which should be used for this HTTP Listener. "+
+						"It has to be the ID of an `ApplicationGatewayWebApplicationFirewallPolicies` resource. Only effective with the `WAF_v2` SKU.",
+					},
+					"custom_error_configuration": {
+						Optional: true,
+						MarkdownDescription: "One or more `custom_error_configuration` blocks as defined below. Each status code can only be declared once.",
+						Attributes: tfsdk.ListNestedAttributes(map[string]tfsdk.Attribute{
+							"status_code": {
+								Type:     types.StringType,
+								Required: true,
+								MarkdownDescription: "Status code of the application gateway customer error. Possible values are `HttpStatus403` and `HttpStatus502`.",
+								Validators: []tfsdk.AttributeValidator{validators.StringInSlice(customErrorStatusCodes, false)},
+							},
+							"custom_error_page_url": {
+								Type:     types.StringType,
+								Required: true,
+								MarkdownDescription: "Error page URL of the application gateway customer error. It has to be a publicly reachable `http` or `https` URL of a `.htm` or `.html` page.",
+							},
+						},tfsdk.ListNestedAttributesOptions{}),
+					},
 				},tfsdk.MapNestedAttributesOptions{}),
 			},
 		},
@@ -445,6 +948,33 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 	applicationGatewayName := plan.Agw_name.Value
 	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
 	
+	//the name of the binding identifies its elements in the registry of the gw (tags)
+	if checkBindingServiceRegistered(gw, plan.Name.Value) {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. A binding named "+plan.Name.Value+" is already registered in the app gateway "+applicationGatewayName+".",
+			"Please, change the name of the binding then retry.",
+		)
+		return
+	}
+	//the elements of another binding can't be modified
+	owned_element, owned := checkBindingServiceOwnership(gw, plan.Name.Value, getBindingServiceElements(plan))
+	if owned {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. This (these) element(s) belong(s) to another binding: \n"+ fmt.Sprint(owned_element),
+			"Please, change its (their) name(s) then retry.",
+		)
+		return
+	}
+	//with adopt_existing, the existing elements with the name of an element of the binding are replaced by the planned ones
+	if plan.Adopt_existing.Value {
+		adopted := adoptBindingServiceElements(&gw, plan)
+		if adopted_element, found := getAdoptedElementList(adopted, nil); found {
+			resp.Diagnostics.AddWarning(
+				"This (these) element(s) existing in the app gateway "+applicationGatewayName+" is (are) adopted by the binding: \n"+ fmt.Sprint(adopted_element),
+				"Its (their) configuration is replaced by the one of the binding.",
+			)
+		}
+	}
 	//Check if the agw already contains an existing element that has the same name of a new element to add
 	exist_element, exist := checkElementName(gw, plan)
 	if exist {
@@ -470,11 +1000,44 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 			plan.Backend_address_pool))
 	
 	/************* generate and add request Routing Rule Map **************/
-	for key, requestRoutingRule_plan := range plan.Request_routing_rules {
+	priority_range, err := getPriorityRange(plan.Priority_range)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. Invalid priority_range: "+err.Error(),
+			"Please, change the priority range then retry.",
+		)
+		return
+	}
+	reserved_priorities := getRequestRoutingRulePlannedPriorities(plan.Request_routing_rules)
+	//the rules are processed in the order of their keys, so the generated priorities are always the same
+	for _, key := range getRequestRoutingRuleSortedKeys(plan.Request_routing_rules) {
+		requestRoutingRule_plan := plan.Request_routing_rules[key]
 		if checkRequestRoutingRuleCreate(key, plan, gw, resp){
 			return
 		}
-		priority := generatePriority(gw,"high")
+		//use the priority provided in the configuration, otherwise generate a free one
+		priority, exist := getRequestRoutingRulePriority(requestRoutingRule_plan)
+		if exist {
+			priority_holder := getRequestRoutingRulePriorityHolder(gw, priority, map[string]bool{})
+			if priority_holder != "" {
+				resp.Diagnostics.AddError(
+					"Unable to create binding. The priority ("+fmt.Sprint(priority)+") declared in Request_routing_rule: "+
+					requestRoutingRule_plan.Name.Value+" is already used by the request routing rule: "+priority_holder,
+					"Please, change the priority then retry.",
+				)
+				return
+			}
+		}else{
+			priority, err = generatePriority(gw,priority_range,reserved_priorities)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to create binding. No priority can be allocated to the Request_routing_rule: "+
+					requestRoutingRule_plan.Name.Value+", "+err.Error(),
+					"Please, set its priority or change the priority range then retry.",
+				)
+				return
+			}
+		}
 		requestRoutingRule_json := createRequestRoutingRule(&requestRoutingRule_plan,priority,
 			r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
 		gw.Properties.RequestRoutingRules = append(gw.Properties.RequestRoutingRules,requestRoutingRule_json)
@@ -490,6 +1053,9 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 	
 	
 	/************* generate and add probe **************/
+	if checkProbeCreate(plan, gw, resp) {
+		return
+	}
 	gw.Properties.Probes = append(gw.Properties.Probes,
 		createProbe(plan.Probe,r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName))
 
@@ -503,9 +1069,6 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 	}	
 	
 	/************* generate and add ssl Certificate **************/
-	if checkSslCertificateCreate(plan, gw, resp) {
-		return
-	}
 	sslCertificate_json := createSslCertificate(plan.Ssl_certificate,
 		r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
 	gw.Properties.SslCertificates = append(gw.Properties.SslCertificates,sslCertificate_json)
@@ -518,12 +1081,74 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 		r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
 	gw.Properties.RedirectConfigurations = append(gw.Properties.RedirectConfigurations,redirectConfiguration_json)
 
+	/************* generate and add Trusted Client Certificate Map **************/
+	for _, trustedClientCertificate_plan := range plan.Trusted_client_certificates {
+		if checkTrustedClientCertificateCreate(trustedClientCertificate_plan, gw, resp) {
+			return
+		}
+		trustedClientCertificate_json := createTrustedClientCertificate(trustedClientCertificate_plan)
+		gw.Properties.TrustedClientCertificates = append(gw.Properties.TrustedClientCertificates,trustedClientCertificate_json)
+	}
+
+	/************* generate and add SSL Profile Map **************/
+	for _, sslProfile_plan := range plan.Ssl_profiles {
+		if checkSslProfileCreate(sslProfile_plan, plan, gw, resp) {
+			return
+		}
+		sslProfile_json := createSslProfile(sslProfile_plan,r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
+		gw.Properties.SslProfiles = append(gw.Properties.SslProfiles,sslProfile_json)
+	}
+
+	/************* generate and add Rewrite Rule Set Map **************/
+	for _, rewriteRuleSet_plan := range plan.Rewrite_rule_sets {
+		if checkRewriteRuleSetCreate(rewriteRuleSet_plan, gw, resp) {
+			return
+		}
+		rewriteRuleSet_json := createRewriteRuleSet(rewriteRuleSet_plan)
+		gw.Properties.RewriteRuleSets = append(gw.Properties.RewriteRuleSets,rewriteRuleSet_json)
+	}
+
+	/************* generate and add URL Path Map Map **************/
+	for _, urlPathMap_plan := range plan.Url_path_maps {
+		if checkURLPathMapCreate(urlPathMap_plan, plan, gw, resp) {
+			return
+		}
+		urlPathMap_json := createURLPathMap(urlPathMap_plan,r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
+		gw.Properties.URLPathMaps = append(gw.Properties.URLPathMaps,urlPathMap_json)
+	}
+
+	//register the elements of the binding in the tags of the gw, they are updated in the same call
+	setBindingServiceOwnership(&gw, plan.Name.Value, getBindingServiceElements(plan))
+	if checkGatewayTagCount(gw) {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. The app gateway "+applicationGatewayName+" would have "+strconv.Itoa(len(gw.Tags))+" tags, "+
+			"Azure allows at most "+strconv.Itoa(maxTagCount)+" tags per resource (the elements of each binding are registered in tags).",
+			"Please, remove some tags of the app gateway or reduce the number of elements of the bindings then retry.",
+		)
+		return
+	}
 
+	//resolve every reference of the gw before sending it, Azure only returns a generic error for a broken one
+	if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+		resp.Diagnostics.AddError(
+			"Unable to create binding. The application gateway would hold reference(s) to missing element(s): "+fmt.Sprint(broken_reference_list),
+			"Please, check the references of the listed elements then retry.",
+		)
+		return
+	}
 	//call the API to update the gw
 	gw_response, error_json, code := updateGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, gw, r.p.token.Access_token)
 	
 	//printToFile(error_json,"updateGW_create.json")
 	//verify if the API response is 200 (that means, normaly, elements were added to the gateway), otherwise exit error
+	//the gateway was modified since it was read (etag of the If-Match header)
+	if code == 412 {
+		resp.Diagnostics.AddError(
+			"Unable to create the resource. The app gateway "+applicationGatewayName+" was modified since it was read (API response = 412).",
+			"Please, run terraform plan and apply again, the app gateway will be read again.",
+		)
+		return
+	}
 	if code != 200 {
 		// Error  - backend address pool wasn't added to the app gateway
 		resp.Diagnostics.AddError(
@@ -538,24 +1163,68 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 	nb_IpAddress	:= len(plan.Backend_address_pool.Ip_addresses)
 	backendAddressPool_state 		:= generateBackendAddressPoolState(gw_response,plan.Backend_address_pool.Name.Value,nb_Fqdns,nb_IpAddress)
 	backendHTTPSettings_state 		:= generateBackendHTTPSettingsState(gw_response,plan.Backend_http_settings.Name.Value)
+	backendHTTPSettings_state.Connection_draining = getConnectionDrainingState(backendHTTPSettings_state.Connection_draining, plan.Backend_http_settings.Connection_draining)
 	probe_state 					:= generateProbeState(gw_response,plan.Probe.Name.Value)
+	probe_state.Match 				= getProbeMatchState(probe_state.Match, plan.Probe.Match)
 	sslCertificate_state 			:= generateSslCertificateState(gw_response,plan.Ssl_certificate.Name.Value)
+	sslCertificate_state 			= getSslCertificatePFXState(sslCertificate_state, plan.Ssl_certificate, gw_response)
+	sslCertificate_state 			= getExpiryWarningDaysState(sslCertificate_state, plan.Ssl_certificate)
 	redirectConfiguration_state 	:= generateRedirectConfigurationState(gw_response,plan.Redirect_configuration.Name.Value)
 	
 	httpListeners_state := make(map [string]Http_listener, len(plan.Http_listeners))
 	for key, value := range plan.Http_listeners { 
 		httpListeners_state[key] = generateHTTPListenerState(gw_response,value.Name.Value)
+		httpListener_state := httpListeners_state[key]
+		httpListener_state.Firewall_policy_id = getFirewallPolicyIDState(httpListener_state.Firewall_policy_id, value.Firewall_policy_id)
+		httpListeners_state[key] = httpListener_state
 	}
 	requestRoutingRules_state := make(map [string]Request_routing_rule, len(plan.Request_routing_rules))
 	for key, value := range plan.Request_routing_rules { 
 		requestRoutingRules_state[key] = generateRequestRoutingRuleState(gw_response,value.Name.Value)
 	}
-	
-	var result BindingService
+	//the url path maps are optional, so the state stays null if they are not declared
+	var urlPathMaps_state map [string]Url_path_map
+	if plan.Url_path_maps != nil {
+		urlPathMaps_state = make(map [string]Url_path_map, len(plan.Url_path_maps))
+		for key, value := range plan.Url_path_maps {
+			urlPathMaps_state[key] = generateURLPathMapState(gw_response,value.Name.Value,value.Path_rules)
+		}
+	}
+	//the rewrite rule sets are optional, so the state stays null if they are not declared
+	var rewriteRuleSets_state map [string]Rewrite_rule_set
+	if plan.Rewrite_rule_sets != nil {
+		rewriteRuleSets_state = make(map [string]Rewrite_rule_set, len(plan.Rewrite_rule_sets))
+		for key, value := range plan.Rewrite_rule_sets {
+			rewriteRuleSets_state[key] = generateRewriteRuleSetState(gw_response,value.Name.Value,value.Rewrite_rules)
+		}
+	}
+	//the ssl profiles are optional, so the state stays null if they are not declared
+	var sslProfiles_state map [string]Ssl_profile
+	if plan.Ssl_profiles != nil {
+		sslProfiles_state = make(map [string]Ssl_profile, len(plan.Ssl_profiles))
+		for key, value := range plan.Ssl_profiles {
+			sslProfiles_state[key] = generateSslProfileState(gw_response,value.Name.Value)
+		}
+	}
+	//the trusted client certificates are optional, so the state stays null if they are not declared
+	var trustedClientCertificates_state map [string]Trusted_client_certificate
+	if plan.Trusted_client_certificates != nil {
+		trustedClientCertificates_state = make(map [string]Trusted_client_certificate, len(plan.Trusted_client_certificates))
+		for key, value := range plan.Trusted_client_certificates {
+			trustedClientCertificates_state[key] = generateTrustedClientCertificateState(gw_response,value.Name.Value,value.Data)
+		}
+	}
+	
+	var result BindingService
 	result = BindingService{
 		Name						: plan.Name,
+		Id							: types.String{Value: getBindingServiceID(gw_response.ID, plan.Name.Value)},
+		Gateway_id					: types.String{Value: gw_response.ID},
 		Agw_name					: types.String{Value: gw_response.Name},
 		Agw_rg						: plan.Agw_rg,
+		Adopt_existing				: plan.Adopt_existing,
+		Detach_shared_references	: plan.Detach_shared_references,
+		Priority_range				: plan.Priority_range,
 		Backend_address_pool		: backendAddressPool_state,
 		Backend_http_settings		: backendHTTPSettings_state,
 		Probe						: probe_state,
@@ -563,6 +1232,10 @@ func (r resourceBindingService) Create(ctx context.Context, req tfsdk.CreateReso
 		Redirect_configuration		: redirectConfiguration_state,
 		Http_listeners				: httpListeners_state,
 		Request_routing_rules		: requestRoutingRules_state,
+		Url_path_maps				: urlPathMaps_state,
+		Rewrite_rule_sets			: rewriteRuleSets_state,
+		Ssl_profiles				: sslProfiles_state,
+		Trusted_client_certificates	: trustedClientCertificates_state,
 	}
 	
 	//store to the created object to the terraform state
@@ -595,7 +1268,19 @@ func (r resourceBindingService) Read(ctx context.Context, req tfsdk.ReadResource
 		"redirectConfigurationName"		: state.Redirect_configuration.Name.Value,		
 	}
 	
-	state = getBindingServiceState(r.p.AZURE_SUBSCRIPTION_ID, names_map, state.Http_listeners, state.Request_routing_rules, r.p.token.Access_token)
+	//the priority range and the options of the binding are only used by the provider, they don't exist in the gateway
+	priority_range := state.Priority_range
+	adopt_existing := state.Adopt_existing
+	detach_shared_references := state.Detach_shared_references
+	connectionDraining_prior := state.Backend_http_settings.Connection_draining
+	match_prior := state.Probe.Match
+	state = getBindingServiceState(r.p.AZURE_SUBSCRIPTION_ID, names_map, state.Http_listeners, state.Request_routing_rules, 
+		state.Url_path_maps, state.Rewrite_rule_sets, state.Ssl_profiles, state.Trusted_client_certificates, state.Ssl_certificate, r.p.token.Access_token)
+	state.Priority_range = priority_range
+	state.Adopt_existing = adopt_existing
+	state.Detach_shared_references = detach_shared_references
+	state.Backend_http_settings.Connection_draining = getConnectionDrainingState(state.Backend_http_settings.Connection_draining, connectionDraining_prior)
+	state.Probe.Match = getProbeMatchState(state.Probe.Match, match_prior)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -623,22 +1308,53 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 		return
 	}
 
-	//check if there is an update of the gw name or resource group, otherwise, issue exit error. 
-	//Propose to remove (destroy) the binding from the initial gw and create a new one in the new gw
-	if (plan.Agw_rg.Value != state.Agw_rg.Value) || (plan.Agw_name.Value != state.Agw_name.Value) {
-		//this is an error. issue an exit error.
+	//Get the agw in order to update it with new values from plan.
+	//a change of the gw name or resource group replaces the binding, so the gw is the one of the state
+	resourceGroupName := plan.Agw_rg.Value
+	applicationGatewayName := plan.Agw_name.Value
+	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
+
+	//a renamed binding can't take the name of another binding of the gw
+	if plan.Name.Value != state.Name.Value && checkBindingServiceRegistered(gw, plan.Name.Value) {
 		resp.Diagnostics.AddError(
-			"You are willing to update the binding app gateway name or resource group name. This is not supported currently. "+ 
-			"We recommand you to make a destroy of the existing binding before creating a new one with the suitable app gateway name and resource group.",
-			"Please, change configuration then retry.",
+			"Unable to update binding. A binding named "+plan.Name.Value+" is already registered in the app gateway "+applicationGatewayName+".",
+			"Please, change the name of the binding then retry.",
+		)
+		return
+	}
+	//the elements removed or added by the binding can't belong to another binding
+	owned_element, owned := checkBindingServiceOwnership(gw, state.Name.Value,
+		append(getBindingServiceElements(state), getBindingServiceElements(plan)...))
+	if owned {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. This (these) element(s) belong(s) to another binding: \n"+ fmt.Sprint(owned_element),
+			"Please, change its (their) name(s) then retry.",
+		)
+		return
+	}
+	//with adopt_existing, the existing elements with the name of a new element of the binding are replaced by the planned ones
+	if plan.Adopt_existing.Value {
+		adopted := adoptBindingServiceElements(&gw, plan)
+		if adopted_element, found := getAdoptedElementList(adopted, getBindingServiceElements(state)); found {
+			resp.Diagnostics.AddWarning(
+				"This (these) element(s) existing in the app gateway "+applicationGatewayName+" is (are) adopted by the binding: \n"+ fmt.Sprint(adopted_element),
+				"Its (their) configuration is replaced by the one of the binding.",
+			)
+		}
+	}
+	//the other elements of the gw can't keep references to the elements removed or renamed by the binding
+	dangling := getDanglingReferences(&gw, getRemovedBindingServiceElements(state, plan),
+		append(getBindingServiceElements(state), getBindingServiceElements(plan)...))
+	if plan.Detach_shared_references.Value {
+		dangling = detachGatewayReferences(dangling)
+	}
+	if len(dangling) != 0 {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. The element(s) removed or renamed by the binding are still referenced by: \n"+ fmt.Sprint(getReferenceList(dangling)),
+			"Please, remove these references (or set detach_shared_references for the optional ones) then retry.",
 		)
 		return
 	}
-
-	//Get the agw in order to update it with new values from plan
-	resourceGroupName := plan.Agw_rg.Value
-	applicationGatewayName := plan.Agw_name.Value
-	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
 
 	//for all elements (attributes), prepare the new elements (json) from the plan
 	//Verify if the agw already contains the elements to be updated beacause:
@@ -703,6 +1419,9 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 	// *********** Processing the probe *********** //	
 	//preparing the new elements (json) from the plan
 	probe_plan := plan.Probe	
+	if checkProbeUpdate(plan, gw, resp) {
+		return
+	}
 	probe_json := createProbe(probe_plan,r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
 
 	//check if the probe name in the plan and state are different,that means that
@@ -776,23 +1495,60 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 
 	var priority int 	
 	// *********** Processing request Routing Rule Map *********** //	
-	//preparing the new elements (json) from the plan
-	for key, requestRoutingRule_plan := range plan.Request_routing_rules { 
+	//the priorities held by the request routing rules of the state are released by the update
+	state_rule_names := make(map[string]bool, len(state.Request_routing_rules))
+	for _, requestRoutingRule_state := range state.Request_routing_rules {
+		state_rule_names[requestRoutingRule_state.Name.Value] = true
+	}
+	reserved_priorities := getRequestRoutingRulePlannedPriorities(plan.Request_routing_rules)
+	priority_range, err := getPriorityRange(plan.Priority_range)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. Invalid priority_range: "+err.Error(),
+			"Please, change the priority range then retry.",
+		)
+		return
+	}
+	//preparing the new elements (json) from the plan. 
+	//the rules are processed in the order of their keys, so the generated priorities are always the same
+	for _, key := range getRequestRoutingRuleSortedKeys(plan.Request_routing_rules) { 
+		requestRoutingRule_plan := plan.Request_routing_rules[key]
 		if checkRequestRoutingRuleUpdate(key, plan, gw, resp) {
 			return
 		}
-		//to compute priority, check if Request Routing Rule exist in the state, so we get the old priority
+		//to compute priority, use first the one provided in the configuration. 
+		//otherwise, check if Request Routing Rule exist in the state with a priority, so we get the old priority
 		// else, that means the old Request Routing Rule was removed manually, we have to generate a new priority
 		requestRoutingRule_state, exist := state.Request_routing_rules[key]
-		if exist {
-			if requestRoutingRule_state.Priority.Value != "0" && requestRoutingRule_state.Priority.Value != "" {
+		if planned_priority, ok := getRequestRoutingRulePriority(requestRoutingRule_plan); ok {
+			priority = planned_priority
+			priority_holder := getRequestRoutingRulePriorityHolder(gw, priority, state_rule_names)
+			if priority_holder != "" && priority_holder != requestRoutingRule_plan.Name.Value {
+				resp.Diagnostics.AddError(
+					"Unable to update binding. The priority ("+fmt.Sprint(priority)+") declared in Request_routing_rule: "+
+					requestRoutingRule_plan.Name.Value+" is already used by the request routing rule: "+priority_holder,
+					"Please, change the priority then retry.",
+				)
+				return
+			}
+		}else{
+			state_priority, has_priority := getRequestRoutingRulePriority(requestRoutingRule_state)
+			if exist && has_priority && !checkPriorityInList(state_priority, reserved_priorities) &&
+				state_priority >= priority_range[0] && state_priority <= priority_range[1] {
 				//the priority of new Request_routing_rule_http is already included in gw, so it's ok
-				priority,_ = strconv.Atoi(requestRoutingRule_state.Priority.Value)
+				//unless it's now declared for another request routing rule in the configuration or it's out of the priority range
+				priority = state_priority
 			}else{
-				priority = generatePriority(gw,"high")
+				priority, err = generatePriority(gw,priority_range,reserved_priorities)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Unable to update binding. No priority can be allocated to the Request_routing_rule: "+
+						requestRoutingRule_plan.Name.Value+", "+err.Error(),
+						"Please, set its priority or change the priority range then retry.",
+					)
+					return
+				}
 			}
-		}else{
-			priority = generatePriority(gw,"high")
 		}
 		
 		//new request Routing Rule is ok. now we have to remove the old one
@@ -842,9 +1598,6 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 
 	// *********** Processing SSL Certificate *********** //	
 	//preparing the new elements (json) from the plan
-	if checkSslCertificateUpdate(plan, gw, resp){
-		return
-	}
 	sslCertificate_plan := plan.Ssl_certificate
 	sslCertificate_json := createSslCertificate(plan.Ssl_certificate,
 		r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)
@@ -899,17 +1652,241 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 		removeRedirectConfigurationElement(&gw, state.Redirect_configuration.Name.Value)
 	}
 
+	// *********** Processing Trusted Client Certificate Map *********** //	
+	//preparing the new elements (json) from the plan
+	for key, trustedClientCertificate_plan := range plan.Trusted_client_certificates {
+		if checkTrustedClientCertificateUpdate(trustedClientCertificate_plan, gw, resp) {
+			return
+		}
+		// we have to remove the old trusted client certificate before creating the new one
+		trustedClientCertificate_state, exist := state.Trusted_client_certificates[key]
+		// if the trusted client certificate that exist in the plan exist also in the state
+		if exist && (trustedClientCertificate_plan.Name.Value == trustedClientCertificate_state.Name.Value) {
+			//so remove the old one before adding the new one.
+			removeTrustedClientCertificateElement(&gw, trustedClientCertificate_plan.Name.Value)
+		}else{
+			// it's most likely about trusted client certificate update:
+			//	1) with a new name, 
+			//	2) or with a new key 
+			//	3) or it no longer exist
+			
+			//remove the old trusted client certificate (old name under the same key) from the gateway
+			if exist {
+				removeTrustedClientCertificateElement(&gw, trustedClientCertificate_state.Name.Value)
+			}
+			//check if the trustedClientCertificate_plan name already exist in the old state but under different key, in order to remove it
+			if checkTrustedClientCertificateNameInMap(trustedClientCertificate_plan.Name.Value, state.Trusted_client_certificates) {
+				removeTrustedClientCertificateElement(&gw, trustedClientCertificate_plan.Name.Value)
+			}
+			// now check if the new trusted client certificate name is already used in the gateway
+			if checkTrustedClientCertificateElement(gw, trustedClientCertificate_plan.Name.Value) {
+				//this is an error. issue an exit error.
+				resp.Diagnostics.AddError(
+					"Unable to update the app gateway. The new trusted client certificate name : "+ trustedClientCertificate_plan.Name.Value+" already exists. "+
+					"It can be due to the name of the trusted client certificate you are under declaring",
+					" Please, change the name then retry.",
+				)
+				return
+			}
+		}
+		trustedClientCertificate_json := createTrustedClientCertificate(trustedClientCertificate_plan)	
+		//add the new one to the gw
+		gw.Properties.TrustedClientCertificates = append(gw.Properties.TrustedClientCertificates,trustedClientCertificate_json)
+	}
+	//check if there are some trusted client certificates that exist in the state but no longer exist in the plan
+	//they have to be removed from the gateway
+	for _, trustedClientCertificate_state := range state.Trusted_client_certificates {
+		if !checkTrustedClientCertificateNameInMap(trustedClientCertificate_state.Name.Value, plan.Trusted_client_certificates) {
+			removeTrustedClientCertificateElement(&gw, trustedClientCertificate_state.Name.Value)
+		}
+	}
+
+	// *********** Processing SSL Profile Map *********** //	
+	//preparing the new elements (json) from the plan
+	for key, sslProfile_plan := range plan.Ssl_profiles {
+		if checkSslProfileUpdate(sslProfile_plan, plan, gw, resp) {
+			return
+		}
+		// we have to remove the old ssl profile before creating the new one
+		sslProfile_state, exist := state.Ssl_profiles[key]
+		// if the ssl profile that exist in the plan exist also in the state
+		if exist && (sslProfile_plan.Name.Value == sslProfile_state.Name.Value) {
+			//so remove the old one before adding the new one.
+			removeSslProfileElement(&gw, sslProfile_plan.Name.Value)
+		}else{
+			// it's most likely about ssl profile update:
+			//	1) with a new name, 
+			//	2) or with a new key 
+			//	3) or it no longer exist
+			
+			//remove the old ssl profile (old name under the same key) from the gateway
+			if exist {
+				removeSslProfileElement(&gw, sslProfile_state.Name.Value)
+			}
+			//check if the sslProfile_plan name already exist in the old state but under different key, in order to remove it
+			if checkSslProfileNameInMap(sslProfile_plan.Name.Value, state.Ssl_profiles) {
+				removeSslProfileElement(&gw, sslProfile_plan.Name.Value)
+			}
+			// now check if the new ssl profile name is already used in the gateway
+			if checkSslProfileElement(gw, sslProfile_plan.Name.Value) {
+				//this is an error. issue an exit error.
+				resp.Diagnostics.AddError(
+					"Unable to update the app gateway. The new SSL profile name : "+ sslProfile_plan.Name.Value+" already exists. "+
+					"It can be due to the name of the SSL profile you are under declaring",
+					" Please, change the name then retry.",
+				)
+				return
+			}
+		}
+		sslProfile_json := createSslProfile(sslProfile_plan,r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)	
+		//add the new one to the gw
+		gw.Properties.SslProfiles = append(gw.Properties.SslProfiles,sslProfile_json)
+	}
+	//check if there are some ssl profiles that exist in the state but no longer exist in the plan
+	//they have to be removed from the gateway
+	for _, sslProfile_state := range state.Ssl_profiles {
+		if !checkSslProfileNameInMap(sslProfile_state.Name.Value, plan.Ssl_profiles) {
+			removeSslProfileElement(&gw, sslProfile_state.Name.Value)
+		}
+	}
+
+	// *********** Processing Rewrite Rule Set Map *********** //	
+	//preparing the new elements (json) from the plan
+	for key, rewriteRuleSet_plan := range plan.Rewrite_rule_sets {
+		if checkRewriteRuleSetUpdate(rewriteRuleSet_plan, gw, resp) {
+			return
+		}
+		// we have to remove the old rewrite rule set before creating the new one
+		rewriteRuleSet_state, exist := state.Rewrite_rule_sets[key]
+		// if the rewrite rule set that exist in the plan exist also in the state
+		if exist && (rewriteRuleSet_plan.Name.Value == rewriteRuleSet_state.Name.Value) {
+			//so remove the old one before adding the new one.
+			removeRewriteRuleSetElement(&gw, rewriteRuleSet_plan.Name.Value)
+		}else{
+			// it's most likely about rewrite rule set update:
+			//	1) with a new name, 
+			//	2) or with a new key 
+			//	3) or it no longer exist
+			
+			//remove the old rewrite rule set (old name under the same key) from the gateway
+			if exist {
+				removeRewriteRuleSetElement(&gw, rewriteRuleSet_state.Name.Value)
+			}
+			//check if the rewriteRuleSet_plan name already exist in the old state but under different key, in order to remove it
+			if checkRewriteRuleSetNameInMap(rewriteRuleSet_plan.Name.Value, state.Rewrite_rule_sets) {
+				removeRewriteRuleSetElement(&gw, rewriteRuleSet_plan.Name.Value)
+			}
+			// now check if the new rewrite rule set name is already used in the gateway
+			if checkRewriteRuleSetElement(gw, rewriteRuleSet_plan.Name.Value) {
+				//this is an error. issue an exit error.
+				resp.Diagnostics.AddError(
+					"Unable to update the app gateway. The new rewrite rule set name : "+ rewriteRuleSet_plan.Name.Value+" already exists. "+
+					"It can be due to the name of the rewrite rule set you are under declaring",
+					" Please, change the name then retry.",
+				)
+				return
+			}
+		}
+		rewriteRuleSet_json := createRewriteRuleSet(rewriteRuleSet_plan)	
+		//add the new one to the gw
+		gw.Properties.RewriteRuleSets = append(gw.Properties.RewriteRuleSets,rewriteRuleSet_json)
+	}
+	//check if there are some rewrite rule sets that exist in the state but no longer exist in the plan
+	//they have to be removed from the gateway
+	for _, rewriteRuleSet_state := range state.Rewrite_rule_sets {
+		if !checkRewriteRuleSetNameInMap(rewriteRuleSet_state.Name.Value, plan.Rewrite_rule_sets) {
+			removeRewriteRuleSetElement(&gw, rewriteRuleSet_state.Name.Value)
+		}
+	}
+
+	// *********** Processing URL Path Map Map *********** //	
+	//preparing the new elements (json) from the plan
+	for key, urlPathMap_plan := range plan.Url_path_maps {
+		if checkURLPathMapUpdate(urlPathMap_plan, plan, gw, resp) {
+			return
+		}
+		// we have to remove the old url path map before creating the new one
+		urlPathMap_state, exist := state.Url_path_maps[key]
+		// if the url path map that exist in the plan exist also in the state
+		if exist && (urlPathMap_plan.Name.Value == urlPathMap_state.Name.Value) {
+			//so remove the old one before adding the new one.
+			removeURLPathMapElement(&gw, urlPathMap_plan.Name.Value)
+		}else{
+			// it's most likely about url path map update:
+			//	1) with a new name, 
+			//	2) or with a new key 
+			//	3) or it no longer exist
+			
+			//remove the old url path map (old name under the same key) from the gateway
+			if exist {
+				removeURLPathMapElement(&gw, urlPathMap_state.Name.Value)
+			}
+			//check if the urlPathMap_plan name already exist in the old state but under different key, in order to remove it
+			if checkURLPathMapNameInMap(urlPathMap_plan.Name.Value, state.Url_path_maps) {
+				removeURLPathMapElement(&gw, urlPathMap_plan.Name.Value)
+			}
+			// now check if the new url path map name is already used in the gateway
+			if checkURLPathMapElement(gw, urlPathMap_plan.Name.Value) {
+				//this is an error. issue an exit error.
+				resp.Diagnostics.AddError(
+					"Unable to update the app gateway. The new URL path map name : "+ urlPathMap_plan.Name.Value+" already exists. "+
+					"It can be due to the name of the URL path map you are under declaring",
+					" Please, change the name then retry.",
+				)
+				return
+			}
+		}
+		urlPathMap_json := createURLPathMap(urlPathMap_plan,r.p.AZURE_SUBSCRIPTION_ID,resourceGroupName,applicationGatewayName)	
+		//add the new one to the gw
+		gw.Properties.URLPathMaps = append(gw.Properties.URLPathMaps,urlPathMap_json)
+	}
+	//check if there are some url path maps that exist in the state but no longer exist in the plan
+	//they have to be removed from the gateway
+	for _, urlPathMap_state := range state.Url_path_maps {
+		if !checkURLPathMapNameInMap(urlPathMap_state.Name.Value, plan.Url_path_maps) {
+			removeURLPathMapElement(&gw, urlPathMap_state.Name.Value)
+		}
+	}
+
 	//add the new elements (http Listener and Request Routing Rule (HTTP) elements are already added because they are optionals). 
 	gw.Properties.BackendAddressPools = append(gw.Properties.BackendAddressPools, backendAddressPool_json)
 	gw.Properties.BackendHTTPSettingsCollection = append(gw.Properties.BackendHTTPSettingsCollection, backendHTTPSettings_json)
 	gw.Properties.Probes = append(gw.Properties.Probes, probe_json)
 	gw.Properties.SslCertificates = append(gw.Properties.SslCertificates, sslCertificate_json)
 	gw.Properties.RedirectConfigurations = append(gw.Properties.RedirectConfigurations, redirectConfiguration_json)
+
+	//the registry of the binding is replaced, under its new name if it was renamed
+	removeBindingServiceOwnership(&gw, state.Name.Value)
+	setBindingServiceOwnership(&gw, plan.Name.Value, getBindingServiceElements(plan))
+	if checkGatewayTagCount(gw) {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. The app gateway "+applicationGatewayName+" would have "+strconv.Itoa(len(gw.Tags))+" tags, "+
+			"Azure allows at most "+strconv.Itoa(maxTagCount)+" tags per resource (the elements of each binding are registered in tags).",
+			"Please, remove some tags of the app gateway or reduce the number of elements of the bindings then retry.",
+		)
+		return
+	}
 	
+	//resolve every reference of the gw before sending it, Azure only returns a generic error for a broken one
+	if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+		resp.Diagnostics.AddError(
+			"Unable to update binding. The application gateway would hold reference(s) to missing element(s): "+fmt.Sprint(broken_reference_list),
+			"Please, check the references of the listed elements then retry.",
+		)
+		return
+	}
 	//and update the gateway
 	gw_response, error_json, code := updateGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, gw, r.p.token.Access_token)
 	
 	//verify if the API response is 200 (that means, normaly, elements were added to the gateway), otherwise exit error
+	//the gateway was modified since it was read (etag of the If-Match header)
+	if code == 412 {
+		resp.Diagnostics.AddError(
+			"Unable to update the resource. The app gateway "+applicationGatewayName+" was modified since it was read (API response = 412).",
+			"Please, run terraform plan and apply again, the app gateway will be read again.",
+		)
+		return
+	}
 	if code != 200 {
 		// Error  - when adding new elements to the app gateway
 		resp.Diagnostics.AddError(
@@ -937,18 +1914,57 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 	
 	backendAddressPool_state		:= generateBackendAddressPoolState(gw_response, backendAddressPool_json.Name,nb_Fqdns,nb_IpAddress)
 	backendHTTPSettings_state		:= generateBackendHTTPSettingsState(gw_response,backendHTTPSettings_json.Name)
+	backendHTTPSettings_state.Connection_draining = getConnectionDrainingState(backendHTTPSettings_state.Connection_draining, plan.Backend_http_settings.Connection_draining)
 	probe_state						:= generateProbeState(gw_response,probe_json.Name)
+	probe_state.Match 				= getProbeMatchState(probe_state.Match, plan.Probe.Match)
 	sslCertificate_state 			:= generateSslCertificateState(gw_response,sslCertificate_json.Name)
+	sslCertificate_state 			= getSslCertificatePFXState(sslCertificate_state, plan.Ssl_certificate, gw_response)
+	sslCertificate_state 			= getExpiryWarningDaysState(sslCertificate_state, plan.Ssl_certificate)
 	redirectConfiguration_state 	:= generateRedirectConfigurationState(gw_response,redirectConfiguration_json.Name)
 	
 	httpListeners_state := make(map [string]Http_listener, len(plan.Http_listeners))
 	for key, value := range plan.Http_listeners { 
 		httpListeners_state[key] = generateHTTPListenerState(gw_response,value.Name.Value)
+		httpListener_state := httpListeners_state[key]
+		httpListener_state.Firewall_policy_id = getFirewallPolicyIDState(httpListener_state.Firewall_policy_id, value.Firewall_policy_id)
+		httpListeners_state[key] = httpListener_state
 	}
 	requestRoutingRules_state := make(map [string]Request_routing_rule, len(plan.Request_routing_rules))
 	for key, value := range plan.Request_routing_rules { 
 		requestRoutingRules_state[key] = generateRequestRoutingRuleState(gw_response,value.Name.Value)
 	}
+	//the url path maps are optional, so the state stays null if they are not declared
+	var urlPathMaps_state map [string]Url_path_map
+	if plan.Url_path_maps != nil {
+		urlPathMaps_state = make(map [string]Url_path_map, len(plan.Url_path_maps))
+		for key, value := range plan.Url_path_maps {
+			urlPathMaps_state[key] = generateURLPathMapState(gw_response,value.Name.Value,value.Path_rules)
+		}
+	}
+	//the rewrite rule sets are optional, so the state stays null if they are not declared
+	var rewriteRuleSets_state map [string]Rewrite_rule_set
+	if plan.Rewrite_rule_sets != nil {
+		rewriteRuleSets_state = make(map [string]Rewrite_rule_set, len(plan.Rewrite_rule_sets))
+		for key, value := range plan.Rewrite_rule_sets {
+			rewriteRuleSets_state[key] = generateRewriteRuleSetState(gw_response,value.Name.Value,value.Rewrite_rules)
+		}
+	}
+	//the ssl profiles are optional, so the state stays null if they are not declared
+	var sslProfiles_state map [string]Ssl_profile
+	if plan.Ssl_profiles != nil {
+		sslProfiles_state = make(map [string]Ssl_profile, len(plan.Ssl_profiles))
+		for key, value := range plan.Ssl_profiles {
+			sslProfiles_state[key] = generateSslProfileState(gw_response,value.Name.Value)
+		}
+	}
+	//the trusted client certificates are optional, so the state stays null if they are not declared
+	var trustedClientCertificates_state map [string]Trusted_client_certificate
+	if plan.Trusted_client_certificates != nil {
+		trustedClientCertificates_state = make(map [string]Trusted_client_certificate, len(plan.Trusted_client_certificates))
+		for key, value := range plan.Trusted_client_certificates {
+			trustedClientCertificates_state[key] = generateTrustedClientCertificateState(gw_response,value.Name.Value,value.Data)
+		}
+	}
 
 	/*************** Special for Http listener **********************/
 	// Generate resource state struct 
@@ -957,8 +1973,13 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 	var result BindingService
 	result = BindingService{
 		Name						: plan.Name,
+		Id							: types.String{Value: getBindingServiceID(gw_response.ID, plan.Name.Value)},
+		Gateway_id					: types.String{Value: gw_response.ID},
 		Agw_name					: types.String{Value: gw_response.Name},
 		Agw_rg						: plan.Agw_rg,
+		Adopt_existing				: plan.Adopt_existing,
+		Detach_shared_references	: plan.Detach_shared_references,
+		Priority_range				: plan.Priority_range,
 		Backend_address_pool		: backendAddressPool_state,
 		Backend_http_settings		: backendHTTPSettings_state,
 		Probe						: probe_state,
@@ -966,6 +1987,10 @@ func (r resourceBindingService) Update(ctx context.Context, req tfsdk.UpdateReso
 		Redirect_configuration		: redirectConfiguration_state,
 		Http_listeners				: httpListeners_state,
 		Request_routing_rules		: requestRoutingRules_state,
+		Url_path_maps				: urlPathMaps_state,
+		Rewrite_rule_sets			: rewriteRuleSets_state,
+		Ssl_profiles				: sslProfiles_state,
+		Trusted_client_certificates	: trustedClientCertificates_state,
 	}
 	
 	//store to the created objecy to the terraform state
@@ -997,6 +2022,28 @@ func (r resourceBindingService) Delete(ctx context.Context, req tfsdk.DeleteReso
 	resourceGroupName := state.Agw_rg.Value
 	applicationGatewayName := state.Agw_name.Value
 	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
+
+	//the elements of another binding can't be removed
+	owned_element, owned := checkBindingServiceOwnership(gw, state.Name.Value, getBindingServiceElements(state))
+	if owned {
+		resp.Diagnostics.AddError(
+			"Unable to delete binding. This (these) element(s) belong(s) to another binding: \n"+ fmt.Sprint(owned_element),
+			"Please, remove them from the state of this binding then retry.",
+		)
+		return
+	}
+	//the other elements of the gw can't keep references to the removed elements
+	dangling := getDanglingReferences(&gw, getBindingServiceElements(state), getBindingServiceElements(state))
+	if state.Detach_shared_references.Value {
+		dangling = detachGatewayReferences(dangling)
+	}
+	if len(dangling) != 0 {
+		resp.Diagnostics.AddError(
+			"Unable to delete binding. The element(s) of the binding are still referenced by: \n"+ fmt.Sprint(getReferenceList(dangling)),
+			"Please, remove these references (or set detach_shared_references for the optional ones) then retry.",
+		)
+		return
+	}
 	
 	//remove the elements from the gw
 	removeBackendAddressPoolElement(&gw, backendAddressPoolName)
@@ -1011,10 +2058,39 @@ func (r resourceBindingService) Delete(ctx context.Context, req tfsdk.DeleteReso
 	for _, requestRoutingRule_state := range state.Request_routing_rules { 
 		removeRequestRoutingRuleElement(&gw,requestRoutingRule_state.Name.Value)		
 	}
+	for _, urlPathMap_state := range state.Url_path_maps { 
+		removeURLPathMapElement(&gw,urlPathMap_state.Name.Value)		
+	}
+	for _, rewriteRuleSet_state := range state.Rewrite_rule_sets { 
+		removeRewriteRuleSetElement(&gw,rewriteRuleSet_state.Name.Value)		
+	}
+	for _, sslProfile_state := range state.Ssl_profiles { 
+		removeSslProfileElement(&gw,sslProfile_state.Name.Value)		
+	}
+	for _, trustedClientCertificate_state := range state.Trusted_client_certificates { 
+		removeTrustedClientCertificateElement(&gw,trustedClientCertificate_state.Name.Value)		
+	}
+	removeBindingServiceOwnership(&gw, state.Name.Value)
 	
+	//resolve every reference of the gw before sending it, Azure only returns a generic error for a broken one
+	if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+		resp.Diagnostics.AddError(
+			"Unable to delete binding. The application gateway would hold reference(s) to missing element(s): "+fmt.Sprint(broken_reference_list),
+			"Please, check the references of the listed elements then retry.",
+		)
+		return
+	}
 	//and update the gateway
 	_, error_json, code := updateGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, gw, r.p.token.Access_token)
 	//verify if the API response is 200 (that means, normaly, elements were deleted to the gateway), otherwise exit error
+	//the gateway was modified since it was read (etag of the If-Match header)
+	if code == 412 {
+		resp.Diagnostics.AddError(
+			"Unable to delete the resource. The app gateway "+applicationGatewayName+" was modified since it was read (API response = 412).",
+			"Please, run terraform plan and apply again, the app gateway will be read again.",
+		)
+		return
+	}
 	if code != 200 {
 		// Error  - when deleting new elements to the app gateway
 		resp.Diagnostics.AddError(
@@ -1028,61 +2104,762 @@ func (r resourceBindingService) Delete(ctx context.Context, req tfsdk.DeleteReso
 	resp.State.RemoveResource(ctx)
 }
 
+// Validate config: check the rules between the attributes of the binding during terraform validate and plan
+func (r resourceBindingService) ValidateConfig(ctx context.Context, req tfsdk.ValidateResourceConfigRequest, resp *tfsdk.ValidateResourceConfigResponse) {
+	// each block is checked on its own. A block holding unknown values (from other resources)
+	// can't be retrieved yet, it will be checked again during the plan of the apply
+	var backendHTTPSettings Backend_http_settings
+	diags := req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("backend_http_settings"), &backendHTTPSettings)
+	if !diags.HasError() {
+		validateBackendHTTPSettingsConfig(backendHTTPSettings, resp)
+	}
+	var probe Probe_tf
+	diags = req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("probe"), &probe)
+	if !diags.HasError() {
+		validateProbeConfig(probe, resp)
+	}
+	var sslCertificate Ssl_certificate
+	diags = req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("ssl_certificate"), &sslCertificate)
+	if !diags.HasError() {
+		validateSslCertificateConfig(sslCertificate, resp)
+	}
+	var redirectConfiguration Redirect_configuration
+	diags = req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("redirect_configuration"), &redirectConfiguration)
+	if !diags.HasError() {
+		validateRedirectConfigurationConfig(redirectConfiguration, resp)
+	}
+	var httpListeners map[string]Http_listener
+	diags = req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("http_listeners"), &httpListeners)
+	if !diags.HasError() {
+		for _, key := range getHTTPListenerSortedKeys(httpListeners) {
+			validateHTTPListenerConfig(key, httpListeners[key], resp)
+		}
+	}
+	var requestRoutingRules map[string]Request_routing_rule
+	diags = req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("request_routing_rules"), &requestRoutingRules)
+	if !diags.HasError() {
+		for _, key := range getRequestRoutingRuleSortedKeys(requestRoutingRules) {
+			validateRequestRoutingRuleConfig(key, requestRoutingRules[key], resp)
+		}
+	}
+	var urlPathMaps map[string]Url_path_map
+	diags = req.Config.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("url_path_maps"), &urlPathMaps)
+	if !diags.HasError() {
+		for _, key := range getURLPathMapSortedKeys(urlPathMaps) {
+			validateURLPathMapConfig(key, urlPathMaps[key], resp)
+		}
+	}
+}
+
+// Modify plan: check the planned binding against the app gateway during terraform plan
+func (r resourceBindingService) ModifyPlan(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, resp *tfsdk.ModifyResourcePlanResponse) {
+	//nothing to check when the binding is destroyed or when the provider can't call the Azure API yet
+	if req.Plan.Raw.IsNull() || !r.p.configured {
+		return
+	}
+	//when the gateway changes, the binding is replaced: it's removed from the old gateway, so its state is ignored by the checks
+	if !req.State.Raw.IsNull() {
+		var applicationGatewayName_state, resourceGroupName_state, applicationGatewayName_plan, resourceGroupName_plan types.String
+		req.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_name"), &applicationGatewayName_state)
+		req.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_resource_group_name"), &resourceGroupName_state)
+		req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_name"), &applicationGatewayName_plan)
+		req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_resource_group_name"), &resourceGroupName_plan)
+		if !applicationGatewayName_plan.Equal(applicationGatewayName_state) || !resourceGroupName_plan.Equal(resourceGroupName_state) {
+			req.State.Raw = tftypes.NewValue(req.State.Schema.TerraformType(ctx), nil)
+		}
+	}
+
+	// Retrieve the request routing rules from plan and state.
+	// if some values are not known yet, the checks will be done during the apply
+	var requestRoutingRules_plan map[string]Request_routing_rule
+	diags := req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("request_routing_rules"), &requestRoutingRules_plan)
+	if diags.HasError() {
+		return
+	}
+	requestRoutingRules_state := map[string]Request_routing_rule{}
+	if !req.State.Raw.IsNull() {
+		diags = req.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("request_routing_rules"), &requestRoutingRules_state)
+		if diags.HasError() {
+			return
+		}
+	}
+	var applicationGatewayName, resourceGroupName types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_name"), &applicationGatewayName)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_resource_group_name"), &resourceGroupName)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// *********** Checking request Routing Rule priorities *********** //
+	var priorityRange_plan []types.Int64
+	diags = req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("priority_range"), &priorityRange_plan)
+	if !diags.HasError() && !checkUnknownInt64InList(priorityRange_plan) {
+		if _, err := getPriorityRange(priorityRange_plan); err != nil {
+			resp.Diagnostics.AddAttributeError(tftypes.NewAttributePath().WithAttributeName("priority_range"),
+				"Invalid priority_range: "+err.Error(),
+				"Please, change the priority range then retry.",
+			)
+		}
+	}
+	keys := getRequestRoutingRuleSortedKeys(requestRoutingRules_plan)
+	//the rules of the binding (old and new ones) will be replaced, so they don't hold their priorities
+	binding_rule_names := make(map[string]bool)
+	for _, requestRoutingRule_state := range requestRoutingRules_state {
+		binding_rule_names[requestRoutingRule_state.Name.Value] = true
+	}
+	for _, requestRoutingRule_plan := range requestRoutingRules_plan {
+		binding_rule_names[requestRoutingRule_plan.Name.Value] = true
+	}
+	priority_keys := make(map[int]string)
+	for _, key := range keys {
+		requestRoutingRule_plan := requestRoutingRules_plan[key]
+		if requestRoutingRule_plan.Priority.Null || requestRoutingRule_plan.Priority.Unknown {
+			continue
+		}
+		priority_path := tftypes.NewAttributePath().WithAttributeName("request_routing_rules").WithElementKeyString(key).WithAttributeName("priority")
+		if err := checkRequestRoutingRulePriority(requestRoutingRule_plan.Priority.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(priority_path,
+				"Invalid priority in Request_routing_rule: "+requestRoutingRule_plan.Name.Value+", "+err.Error()+".",
+				"Please, change the priority then retry.",
+			)
+			continue
+		}
+		priority := int(requestRoutingRule_plan.Priority.Value)
+		if other_key, exist := priority_keys[priority]; exist {
+			resp.Diagnostics.AddAttributeError(priority_path,
+				"The priority ("+fmt.Sprint(priority)+") declared in Request_routing_rule: "+requestRoutingRule_plan.Name.Value+
+				" is already declared in Request_routing_rule: "+requestRoutingRules_plan[other_key].Name.Value,
+				"Please, change the priority then retry.",
+			)
+			continue
+		}
+		priority_keys[priority] = key
+	}
+
+	// *********** Checking the SSL certificate *********** //
+	//the PFX is decoded locally, so a wrong password or a missing private key is reported before any call to the gateway
+	var sslCertificate_plan Ssl_certificate
+	var certificate *x509.Certificate
+	diags = req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("ssl_certificate"), &sslCertificate_plan)
+	if !diags.HasError() && !sslCertificate_plan.Data.Unknown && !sslCertificate_plan.Password.Unknown && sslCertificate_plan.Data.Value != "" {
+		var err error
+		if certificate, err = parsePFX(sslCertificate_plan.Data.Value, sslCertificate_plan.Password.Value); err != nil {
+			resp.Diagnostics.AddAttributeError(tftypes.NewAttributePath().WithAttributeName("ssl_certificate").WithAttributeName("data"),
+				"Invalid PFX in SSL Certificate: "+sslCertificate_plan.Name.Value+", "+err.Error()+".",
+				"Please, change the data or the password then retry.",
+			)
+		}
+	}else if !diags.HasError() && !req.State.Raw.IsNull() {
+		//the key vault certificate is only known through the public certificate data of the last refresh
+		var sslCertificate_state Ssl_certificate
+		diags = req.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("ssl_certificate"), &sslCertificate_state)
+		if !diags.HasError() && sslCertificate_state.Public_cert_data.Value != "" &&
+			sslCertificate_state.Key_vault_secret_id.Value == sslCertificate_plan.Key_vault_secret_id.Value {
+			certificate, _ = getPublicCertDataCertificate(sslCertificate_state.Public_cert_data.Value)
+		}
+	}
+	key_vault_secret_id_path := tftypes.NewAttributePath().WithAttributeName("ssl_certificate").WithAttributeName("key_vault_secret_id")
+	check_key_vault_identity := false
+	if !diags.HasError() && checkStringConfigured(sslCertificate_plan.Key_vault_secret_id) {
+		//the format of the secret ID is checked by validateSslCertificateConfig
+		_, err := parseKeyVaultSecretID(sslCertificate_plan.Key_vault_secret_id.Value)
+		check_key_vault_identity = err == nil
+	}
+	if certificate != nil && !sslCertificate_plan.Expiry_warning_days.Unknown {
+		expiry_warning_days := int64(defaultExpiryWarningDays)
+		if !sslCertificate_plan.Expiry_warning_days.Null {
+			expiry_warning_days = sslCertificate_plan.Expiry_warning_days.Value
+		}
+		if warning, expire := checkSslCertificateExpiry(certificate, expiry_warning_days); expire {
+			resp.Diagnostics.AddAttributeWarning(tftypes.NewAttributePath().WithAttributeName("ssl_certificate"),
+				"SSL Certificate: "+sslCertificate_plan.Name.Value+", "+warning+".",
+				"Please, renew the certificate.",
+			)
+		}
+	}
+
+	// *********** Checking WAF policies *********** //
+	//the firewall policies of the http listeners and the path rules are only effective with the WAF_v2 SKU
+	var firewall_policy_paths []*tftypes.AttributePath
+	var httpListeners_plan map[string]Http_listener
+	diags = req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("http_listeners"), &httpListeners_plan)
+	if !diags.HasError() {
+		for _, key := range getHTTPListenerSortedKeys(httpListeners_plan) {
+			httpListener_plan := httpListeners_plan[key]
+			if !httpListener_plan.Firewall_policy_id.Null && !httpListener_plan.Firewall_policy_id.Unknown {
+				firewall_policy_paths = append(firewall_policy_paths,
+					tftypes.NewAttributePath().WithAttributeName("http_listeners").WithElementKeyString(key).WithAttributeName("firewall_policy_id"))
+			}
+		}
+	}
+	var urlPathMaps_plan map[string]Url_path_map
+	diags = req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("url_path_maps"), &urlPathMaps_plan)
+	if !diags.HasError() {
+		for _, key := range getURLPathMapSortedKeys(urlPathMaps_plan) {
+			urlPathMap_plan := urlPathMaps_plan[key]
+			for _, key_rule := range getPathRuleSortedKeys(urlPathMap_plan.Path_rules) {
+				pathRule_plan := urlPathMap_plan.Path_rules[key_rule]
+				if !pathRule_plan.Firewall_policy_id.Null && !pathRule_plan.Firewall_policy_id.Unknown {
+					firewall_policy_paths = append(firewall_policy_paths,
+						tftypes.NewAttributePath().WithAttributeName("url_path_maps").WithElementKeyString(key).
+						WithAttributeName("path_rules").WithElementKeyString(key_rule).WithAttributeName("firewall_policy_id"))
+				}
+			}
+		}
+	}
+
+	if resp.Diagnostics.HasError() || applicationGatewayName.Unknown || resourceGroupName.Unknown {
+		return
+	}
+	//the gateway is fetched once, all the checks below are done against it
+	gw, code, err := readGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName.Value, applicationGatewayName.Value, r.p.token.Access_token)
+	if code == http.StatusNotFound {
+		//the gateway may be created by the same apply, so the checks are done during the apply
+		return
+	}
+	if err != nil {
+		//the checks against the gateway are done again during the apply
+		resp.Diagnostics.AddWarning(
+			"Unable to read the app gateway "+applicationGatewayName.Value+", the binding is not checked against it during the plan: "+err.Error(),
+			"Please, check the connection to Azure and the permissions on the app gateway. The checks will be done during the apply.",
+		)
+		return
+	}
+	if gw.Properties.Sku.Tier != "WAF_v2" {
+		for i := 0; i < len(firewall_policy_paths); i++ {
+			resp.Diagnostics.AddAttributeWarning(firewall_policy_paths[i],
+				"The tier of the app gateway "+gw.Name+" is "+gw.Properties.Sku.Tier+", the firewall policy will have no effect.",
+				"Web Application Firewall policies are only enforced by the WAF_v2 SKU. Please, upgrade the gateway or remove the firewall policy.",
+			)
+		}
+	}
+	//the gateway reads the Key Vault secrets with its managed identity
+	if check_key_vault_identity && gw.Name != "" && gw.Identity == nil {
+		resp.Diagnostics.AddAttributeWarning(key_vault_secret_id_path,
+			"The app gateway "+gw.Name+" has no managed identity, it won't be able to read the Key Vault secret of the SSL Certificate: "+
+			sslCertificate_plan.Name.Value+".",
+			"Please, assign a managed identity with the permission to get the secrets of the Key Vault to the gateway.",
+		)
+	}
+	for _, key := range keys {
+		requestRoutingRule_plan := requestRoutingRules_plan[key]
+		if requestRoutingRule_plan.Priority.Null || requestRoutingRule_plan.Priority.Unknown {
+			continue
+		}
+		//only the valid priorities, reported once by the first rule declaring them, are checked against the gateway
+		priority := int(requestRoutingRule_plan.Priority.Value)
+		if priority_keys[priority] != key {
+			continue
+		}
+		priority_holder := getRequestRoutingRulePriorityHolder(gw, priority, binding_rule_names)
+		if priority_holder != "" {
+			resp.Diagnostics.AddAttributeError(
+				tftypes.NewAttributePath().WithAttributeName("request_routing_rules").WithElementKeyString(key).WithAttributeName("priority"),
+				"The priority ("+fmt.Sprint(priority)+") declared in Request_routing_rule: "+requestRoutingRules_plan[key].Name.Value+
+				" is already used by the request routing rule: "+priority_holder,
+				"Please, change the priority then retry.",
+			)
+		}
+	}
+
+	// *********** Checking the names and the references against the app gateway *********** //
+	checkBindingPlanGateway(ctx, req, gw, resp)
+
+	// *********** Setting the IDs of the elements *********** //
+	setBindingPlanIDs(ctx, req, gw, resp)
+}
+
+// the elements of the binding, with their collection in the gateway and the function checking if an element with the same name exists in it
+type bindingElement struct {
+	attribute	string
+	label		string
+	single		bool
+	collection	string
+	exist		func(ApplicationGateway, string) bool
+}
+
+var bindingElements = []bindingElement{
+	{"backend_address_pool", "Backend_address_pool", true, "backendAddressPools", checkBackendAddressPoolElement},
+	{"backend_http_settings", "Backend_http_settings", true, "backendHttpSettingsCollection", checkBackendHTTPSettingsElement},
+	{"probe", "Probe", true, "probes", checkProbeElement},
+	{"ssl_certificate", "SSL Certificate", true, "sslCertificates", checkSslCertificateElement},
+	{"redirect_configuration", "Redirect_configuration", true, "redirectConfigurations", checkRedirectConfigurationElement},
+	{"http_listeners", "Http_listener", false, "httpListeners", checkHTTPListenerElement},
+	{"request_routing_rules", "Request_routing_rule", false, "requestRoutingRules", checkRequestRoutingRuleElement},
+	{"url_path_maps", "Url_path_map", false, "urlPathMaps", checkURLPathMapElement},
+	{"rewrite_rule_sets", "Rewrite_rule_set", false, "rewriteRuleSets", checkRewriteRuleSetElement},
+	{"ssl_profiles", "Ssl_profile", false, "sslProfiles", checkSslProfileElement},
+	{"trusted_client_certificates", "Trusted_client_certificate", false, "trustedClientCertificates", checkTrustedClientCertificateElement},
+}
+
+// the names of the elements of one kind: declared in the plan (name -> key) and held by the state
+type bindingElementNames struct {
+	element		bindingElement
+	declared	map[string]string
+	owned		map[string]bool
+	known		bool
+}
+
+// tfsdk.Plan and tfsdk.State
+type bindingAttributeGetter interface {
+	GetAttribute(ctx context.Context, path *tftypes.AttributePath, target interface{}) diag.Diagnostics
+}
+
+func getBindingElementPath(element bindingElement, key string) *tftypes.AttributePath {
+	path := tftypes.NewAttributePath().WithAttributeName(element.attribute)
+	if element.single {
+		return path
+	}
+	return path.WithElementKeyString(key)
+}
+func getBindingMapSortedKeys(ctx context.Context, data bindingAttributeGetter, path *tftypes.AttributePath) ([]string, bool) {
+	//return the sorted keys of a map attribute, and false when the map is not known yet
+	var elements types.Map
+	if diags := data.GetAttribute(ctx, path, &elements); diags.HasError() || elements.Unknown {
+		return nil, false
+	}
+	keys := make([]string, 0, len(elements.Elems))
+	for key := range elements.Elems {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, true
+}
+func getBindingString(ctx context.Context, data bindingAttributeGetter, path *tftypes.AttributePath) (string, bool) {
+	//return the value of a string attribute, and false when it's not set or not known yet
+	var value types.String
+	if diags := data.GetAttribute(ctx, path, &value); diags.HasError() || !checkStringConfigured(value) {
+		return "", false
+	}
+	return value.Value, true
+}
+func getBindingElementNames(ctx context.Context, data bindingAttributeGetter, element bindingElement) ([]string, map[string]string, bool) {
+	//return the sorted keys and the names (by key) of the elements of one kind, and false when some names are not known yet
+	keys := []string{""}
+	if !element.single {
+		var known bool
+		if keys, known = getBindingMapSortedKeys(ctx, data, tftypes.NewAttributePath().WithAttributeName(element.attribute)); !known {
+			return nil, nil, false
+		}
+	}
+	names := make(map[string]string, len(keys))
+	all_known := true
+	for _, key := range keys {
+		name, known := getBindingString(ctx, data, getBindingElementPath(element, key).WithAttributeName("name"))
+		if !known {
+			all_known = false
+			continue
+		}
+		names[key] = name
+	}
+	return keys, names, all_known
+}
+func checkBindingPlanReference(names bindingElementNames, gw ApplicationGateway, name string) bool {
+	//a reference is valid when the element is declared in the binding or exists in the gw without being removed by the binding.
+	//it's not checked while some names of the binding are not known yet
+	if !names.known {
+		return true
+	}
+	if _, declared := names.declared[name]; declared {
+		return true
+	}
+	return !names.owned[name] && names.element.exist(gw, name)
+}
+func checkBindingPlanGateway(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, gw ApplicationGateway, resp *tfsdk.ModifyResourcePlanResponse) {
+	//check the planned names and references of the binding against the elements of the gateway
+	var bindingServiceName_state string
+	if !req.State.Raw.IsNull() {
+		bindingServiceName_state, _ = getBindingString(ctx, req.State, tftypes.NewAttributePath().WithAttributeName("name"))
+	}
+	if name, known := getBindingString(ctx, req.Plan, tftypes.NewAttributePath().WithAttributeName("name")); known &&
+		name != bindingServiceName_state && checkBindingServiceRegistered(gw, name) {
+		resp.Diagnostics.AddAttributeError(tftypes.NewAttributePath().WithAttributeName("name"),
+			"A binding named "+name+" is already registered in the app gateway "+gw.Name+".",
+			"Please, change the name of the binding then retry.",
+		)
+	}
+	owners := getElementOwners(gw)
+	var adopt_existing types.Bool
+	req.Plan.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("adopt_existing"), &adopt_existing)
+	binding_names := make(map[string]bindingElementNames, len(bindingElements))
+	for _, element := range bindingElements {
+		keys, names_plan, known := getBindingElementNames(ctx, req.Plan, element)
+		names := bindingElementNames{element: element, declared: make(map[string]string), owned: make(map[string]bool), known: known}
+		if !req.State.Raw.IsNull() {
+			_, names_state, _ := getBindingElementNames(ctx, req.State, element)
+			for _, name := range names_state {
+				names.owned[name] = true
+			}
+		}
+		for _, key := range keys {
+			name, exist := names_plan[key]
+			if !exist {
+				continue
+			}
+			path := getBindingElementPath(element, key).WithAttributeName("name")
+			if other_key, declared := names.declared[name]; declared {
+				resp.Diagnostics.AddAttributeError(path,
+					"The name ("+name+") of "+element.label+" ("+key+") is already declared in "+element.label+" ("+other_key+").",
+					"Please, change the name then retry.",
+				)
+				continue
+			}
+			names.declared[name] = key
+			//the elements of the binding are replaced, any other element with the same name belongs to another configuration
+			if !names.owned[name] && element.exist(gw, name) {
+				owner := ""
+				bindingServiceName, exist := owners[getOwnershipKey(element.collection, name)]
+				if exist {
+					owner = " It belongs to the binding "+bindingServiceName+"."
+				}
+				//the element is adopted, unless it belongs to another binding
+				if adopt_existing.Value && !exist {
+					resp.Diagnostics.AddAttributeWarning(path,
+						"The "+element.label+" ("+name+") existing in the app gateway "+gw.Name+" will be adopted by the binding.",
+						"Its configuration will be replaced by the one of the binding.",
+					)
+					continue
+				}
+				resp.Diagnostics.AddAttributeError(path,
+					"The name ("+name+") of "+element.label+" already exists in the app gateway "+gw.Name+"."+owner,
+					"Please, change the name then retry.",
+				)
+			}
+		}
+		binding_names[element.attribute] = names
+	}
+
+	// the frontends of the listeners are not managed by the binding, they have to exist in the gateway
+	listener_keys, _ := getBindingMapSortedKeys(ctx, req.Plan, tftypes.NewAttributePath().WithAttributeName("http_listeners"))
+	for _, key := range listener_keys {
+		path := tftypes.NewAttributePath().WithAttributeName("http_listeners").WithElementKeyString(key)
+		if name, known := getBindingString(ctx, req.Plan, path.WithAttributeName("frontend_ip_configuration_name")); known &&
+			!checkFrontendIPConfigurationElement(gw, name) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("frontend_ip_configuration_name"),
+				"The frontend IP configuration ("+name+") declared in Http_listener ("+key+") doesn't exist in the app gateway "+gw.Name+".",
+				"Please, change the frontend IP configuration name then retry.",
+			)
+		}
+		if name, known := getBindingString(ctx, req.Plan, path.WithAttributeName("frontend_port_name")); known &&
+			!checkFrontendPortElement(gw, name) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("frontend_port_name"),
+				"The frontend port ("+name+") declared in Http_listener ("+key+") doesn't exist in the app gateway "+gw.Name+".",
+				"Please, change the frontend port name then retry.",
+			)
+		}
+		if name, known := getBindingString(ctx, req.Plan, path.WithAttributeName("ssl_profile_name")); known &&
+			!checkBindingPlanReference(binding_names["ssl_profiles"], gw, name) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("ssl_profile_name"),
+				"The SSL profile ("+name+") declared in Http_listener ("+key+") doesn't match any SSL profile declared in the binding "+
+				"or existing in the app gateway "+gw.Name+".",
+				"Please, change the SSL profile name then retry.",
+			)
+		}
+	}
+
+	// the url path maps and the rewrite rule sets used by the rules
+	rule_keys, _ := getBindingMapSortedKeys(ctx, req.Plan, tftypes.NewAttributePath().WithAttributeName("request_routing_rules"))
+	for _, key := range rule_keys {
+		path := tftypes.NewAttributePath().WithAttributeName("request_routing_rules").WithElementKeyString(key)
+		if name, known := getBindingString(ctx, req.Plan, path.WithAttributeName("url_path_map_name")); known &&
+			!checkBindingPlanReference(binding_names["url_path_maps"], gw, name) {
+			resp.Diagnostics.AddAttributeError(path.WithAttributeName("url_path_map_name"),
+				"The url path map ("+name+") declared in Request_routing_rule ("+key+") doesn't match any url path map declared in the binding "+
+				"or existing in the app gateway "+gw.Name+".",
+				"Please, change the url path map name then retry.",
+			)
+		}
+		checkBindingPlanRewriteRuleSet(ctx, req, gw, binding_names["rewrite_rule_sets"], path.WithAttributeName("rewrite_rule_set_name"),
+			"Request_routing_rule ("+key+")", resp)
+	}
+	url_path_map_keys, _ := getBindingMapSortedKeys(ctx, req.Plan, tftypes.NewAttributePath().WithAttributeName("url_path_maps"))
+	for _, key := range url_path_map_keys {
+		path := tftypes.NewAttributePath().WithAttributeName("url_path_maps").WithElementKeyString(key)
+		checkBindingPlanRewriteRuleSet(ctx, req, gw, binding_names["rewrite_rule_sets"], path.WithAttributeName("default_rewrite_rule_set_name"),
+			"Url_path_map ("+key+")", resp)
+		path_rule_keys, _ := getBindingMapSortedKeys(ctx, req.Plan, path.WithAttributeName("path_rules"))
+		for _, key_rule := range path_rule_keys {
+			checkBindingPlanRewriteRuleSet(ctx, req, gw, binding_names["rewrite_rule_sets"],
+				path.WithAttributeName("path_rules").WithElementKeyString(key_rule).WithAttributeName("rewrite_rule_set_name"),
+				"Path_rule ("+key_rule+") of Url_path_map ("+key+")", resp)
+		}
+	}
+
+	// the trusted client certificates used by the SSL profiles
+	ssl_profile_keys, _ := getBindingMapSortedKeys(ctx, req.Plan, tftypes.NewAttributePath().WithAttributeName("ssl_profiles"))
+	for _, key := range ssl_profile_keys {
+		path := tftypes.NewAttributePath().WithAttributeName("ssl_profiles").WithElementKeyString(key).WithAttributeName("trusted_client_certificate_names")
+		var names []types.String
+		if diags := req.Plan.GetAttribute(ctx, path, &names); diags.HasError() {
+			continue
+		}
+		for _, name := range names {
+			if checkStringConfigured(name) && !checkBindingPlanReference(binding_names["trusted_client_certificates"], gw, name.Value) {
+				resp.Diagnostics.AddAttributeError(path,
+					"The trusted client certificate ("+name.Value+") declared in Ssl_profile ("+key+") doesn't match any trusted client certificate "+
+					"declared in the binding or existing in the app gateway "+gw.Name+".",
+					"Please, change the trusted client certificate names then retry.",
+				)
+			}
+		}
+	}
+
+	// the certificates of the backend http settings are not managed by the binding
+	path := tftypes.NewAttributePath().WithAttributeName("backend_http_settings")
+	var names []types.String
+	if diags := req.Plan.GetAttribute(ctx, path.WithAttributeName("authentication_certificate_names"), &names); !diags.HasError() {
+		for _, name := range names {
+			if checkStringConfigured(name) && !checkAuthenticationCertificateElement(gw, name.Value) {
+				resp.Diagnostics.AddAttributeError(path.WithAttributeName("authentication_certificate_names"),
+					"The authentication certificate ("+name.Value+") declared in Backend_http_settings doesn't exist in the app gateway "+gw.Name+".",
+					"Please, change the authentication certificate names then retry.",
+				)
+			}
+		}
+	}
+	names = nil
+	if diags := req.Plan.GetAttribute(ctx, path.WithAttributeName("trusted_root_certificate_names"), &names); !diags.HasError() {
+		for _, name := range names {
+			//the certificate may be created by an azurermagw_trusted_root_certificate resource of the same apply
+			if checkStringConfigured(name) && !checkTrustedRootCertificateElement(gw, name.Value) {
+				resp.Diagnostics.AddAttributeWarning(path.WithAttributeName("trusted_root_certificate_names"),
+					"The trusted root certificate ("+name.Value+") declared in Backend_http_settings doesn't exist yet in the app gateway "+gw.Name+".",
+					"The certificate has to be added to the gateway before the binding, for instance by an azurermagw_trusted_root_certificate resource.",
+				)
+			}
+		}
+	}
+}
+func setBindingPlanIDs(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, gw ApplicationGateway, resp *tfsdk.ModifyResourcePlanResponse) {
+	//the ID of an element only depends on the gateway and on its name, so it's known as soon as its name is known.
+	//the ID of the gateway returned by Azure is used, so the IDs match the ones returned after the apply
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("gateway_id"), gw.ID)...)
+	if name, known := getBindingString(ctx, req.Plan, tftypes.NewAttributePath().WithAttributeName("name")); known {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("id"), getBindingServiceID(gw.ID, name))...)
+	}
+	for _, element := range bindingElements {
+		keys, names, _ := getBindingElementNames(ctx, req.Plan, element)
+		for _, key := range keys {
+			name, known := names[key]
+			if !known {
+				continue
+			}
+			path := getBindingElementPath(element, key)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.WithAttributeName("id"), gw.ID+"/"+element.collection+"/"+name)...)
+			if element.attribute != "url_path_maps" {
+				continue
+			}
+			//the path rules are sub-resources of their url path map
+			path_rule_keys, _ := getBindingMapSortedKeys(ctx, req.Plan, path.WithAttributeName("path_rules"))
+			for _, key_rule := range path_rule_keys {
+				path_rule := path.WithAttributeName("path_rules").WithElementKeyString(key_rule)
+				if rule_name, known := getBindingString(ctx, req.Plan, path_rule.WithAttributeName("name")); known {
+					resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path_rule.WithAttributeName("id"),
+						gw.ID+"/"+element.collection+"/"+name+"/pathRules/"+rule_name)...)
+				}
+			}
+		}
+	}
+}
+func checkBindingPlanRewriteRuleSet(ctx context.Context, req tfsdk.ModifyResourcePlanRequest, gw ApplicationGateway, names bindingElementNames,
+	path *tftypes.AttributePath, element string, resp *tfsdk.ModifyResourcePlanResponse) {
+	if name, known := getBindingString(ctx, req.Plan, path); known && !checkBindingPlanReference(names, gw, name) {
+		resp.Diagnostics.AddAttributeError(path,
+			"The rewrite rule set ("+name+") declared in "+element+" doesn't match any rewrite rule set declared in the binding "+
+			"or existing in the app gateway "+gw.Name+".",
+			"Please, change the rewrite rule set name then retry.",
+		)
+	}
+}
+
+// Upgrade resource state
+func (r resourceBindingService) UpgradeState(ctx context.Context) map[int64]tfsdk.ResourceStateUpgrader {
+	return map[int64]tfsdk.ResourceStateUpgrader{
+		// state version 0 stored the request routing rule priority as a string
+		0: {
+			StateUpgrader: upgradeBindingServiceStateV0toV1,
+		},
+	}
+}
+func upgradeBindingServiceStateV0toV1(ctx context.Context, req tfsdk.UpgradeResourceStateRequest, resp *tfsdk.UpgradeResourceStateResponse) {
+	if req.RawState == nil || req.RawState.JSON == nil {
+		resp.Diagnostics.AddError(
+			"Unable to upgrade binding state. ",
+			"The prior state of the binding can't be read. Please, remove the binding from the state and import it again.",
+		)
+		return
+	}
+	var rawState map[string]interface{}
+	err := json.Unmarshal(req.RawState.JSON, &rawState)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to upgrade binding state. ",
+			"The prior state of the binding can't be unmarshaled: "+err.Error(),
+		)
+		return
+	}
+	//convert the priority of each request routing rule from string to number. An empty priority becomes null
+	if requestRoutingRules, ok := rawState["request_routing_rules"].(map[string]interface{}); ok {
+		for key, value := range requestRoutingRules {
+			requestRoutingRule, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			priority_string, ok := requestRoutingRule["priority"].(string)
+			if !ok {
+				continue
+			}
+			if priority_string == "" {
+				requestRoutingRule["priority"] = nil
+				continue
+			}
+			priority, err := strconv.Atoi(priority_string)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to upgrade binding state. ",
+					"The priority ("+priority_string+") of the Request_routing_rule "+key+" in the prior state is not an integer.",
+				)
+				return
+			}
+			requestRoutingRule["priority"] = priority
+		}
+	}
+	upgradedJSON, err := json.Marshal(rawState)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to upgrade binding state. ",
+			"The upgraded state of the binding can't be marshaled: "+err.Error(),
+		)
+		return
+	}
+	upgradedRawState := tfprotov6.RawState{JSON: upgradedJSON}
+	upgradedState, err := upgradedRawState.Unmarshal(resp.State.Schema.TerraformType(ctx))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to upgrade binding state. ",
+			"The upgraded state of the binding doesn't match the schema: "+err.Error(),
+		)
+		return
+	}
+	resp.State.Raw = upgradedState
+}
+
 // Import resource
 func (r resourceBindingService) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
-	//the ID given in the import command should match exactly the following format:
-	// <gw_name,gw-resourcegroup,backend_address_pool_name,backend_http_settings_name,probe_name,ssl_certificate,
-	//https_listener_name,request_routing_rule_https_name,redirect_configuration_name,
-	//http_listener_name(optional),request_routing_rule_http_name(optional, required if http_listener_name is set)>
-	/*
-	idParts := strings.Split(req.ID, ",")
-	//check if the given ID contains the right number of params (9 or 11)
-	if (len(idParts) != 11 && len(idParts) != 9) {
-        resp.Diagnostics.AddError(
-            "Unexpected Import Identifier. The identifier should be composed of 9 or 11 params matching exactly the following format: \n"+
-			"<gw_name,gw-resourcegroup,backend_address_pool_name,backend_http_settings_name,probe_name,ssl_certificate,"+
-			"https_listener_name,request_routing_rule_https_name,redirect_configuration_name,"+
-			"http_listener_name(optional),request_routing_rule_http_name(optional, required if http_listener_name is set)>",
-            "Please, check the import identifier then retry",
-        )
-        return
-    }
+	//the ID given in the import command is the ID of the binding, built from the ID of its gateway:
+	// /subscriptions/<id>/resourceGroups/<rg>/providers/Microsoft.Network/applicationGateways/<gw>/bindingServices/<name>
+	idParts := strings.Split(strings.Trim(req.ID, "/"), "/")
+	if len(idParts) != 10 || !strings.EqualFold(idParts[0], "subscriptions") || !strings.EqualFold(idParts[2], "resourceGroups") ||
+		!strings.EqualFold(idParts[6], "applicationGateways") || !strings.EqualFold(idParts[8], "bindingServices") {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier. The identifier should be the ID of the binding matching the following format: \n"+
+			"/subscriptions/<subscription_id>/resourceGroups/<resource_group>/providers/Microsoft.Network/applicationGateways/<gateway_name>/"+
+			"bindingServices/<name>",
+			"Please, check the import identifier then retry",
+		)
+		return
+	}
+	resourceGroupName := idParts[3]
+	applicationGatewayName := idParts[7]
+	bindingServiceName := idParts[9]
+	//the gateway is read with the subscription of the provider
+	if !strings.EqualFold(idParts[1], r.p.AZURE_SUBSCRIPTION_ID) {
+		resp.Diagnostics.AddError(
+			"Unable to import binding. The subscription of the identifier ("+idParts[1]+") is not the subscription of the provider ("+
+			r.p.AZURE_SUBSCRIPTION_ID+").",
+			"Please, check the import identifier or the subscription of the provider then retry",
+		)
+		return
+	}
+	gw, code, err := readGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to import binding. The app gateway "+applicationGatewayName+" can't be read (status code "+strconv.Itoa(code)+"): "+err.Error(),
+			"Please, check the import identifier and the connection to Azure then retry",
+		)
+		return
+	}
 
-	//check if there is an empty param
-	for i := 0; i < len(idParts); i++ {
-		if idParts[i] == "" {
-			resp.Diagnostics.AddError(
-				"Unexpected Import Identifier. A given param is empty",
-				"Please, check the import identifier then retry",
-			)
-			return
+	//the elements of the binding are the ones registered in the tags of the gateway
+	elements := getBindingServiceRegisteredElements(gw, bindingServiceName)
+	if len(elements) == 0 {
+		resp.Diagnostics.AddError(
+			"Unable to import binding. No binding named "+bindingServiceName+" is registered in the tags of the app gateway "+applicationGatewayName+".",
+			"Only the bindings created or updated by this provider can be imported. Please, check the import identifier then retry",
+		)
+		return
+	}
+	getSingleElementName := func(collection string) string {
+		if names := elements[collection]; len(names) != 0 {
+			return names[0]
 		}
+		return ""
 	}
-	uniqueId := RandStringBytes(10)
-	
 	names_map := map[string]string{
-		"bindingServiceName"			: "binding_"+uniqueId, //generate random unique name for the imported resource
-		"applicationGatewayName"		: idParts[0],
-		"resourceGroupName"				: idParts[1],
-		"backendAddressPoolName"		: idParts[2],
-		"backendHTTPSettingsName"		: idParts[3],
-		"probeName"						: idParts[4],
-		"sslCertificateName"			: idParts[5],
-		"httpsListenerName"				: idParts[6],
-		"requestRoutingRuleHttpsName"	: idParts[7],
-		"redirectConfigurationName"		: idParts[8],		
-	}
-	if len(idParts) == 11{
-		names_map["httpListenerName"] = idParts[9]
-		names_map["requestRoutingRuleHttpName"] = idParts[10]
-	}
-
-	state := getBindingServiceState(r.p.AZURE_SUBSCRIPTION_ID,names_map,r.p.token.Access_token)
+		"bindingServiceName"			: bindingServiceName,
+		"applicationGatewayName"		: applicationGatewayName,
+		"resourceGroupName"				: resourceGroupName,
+		"backendAddressPoolName"		: getSingleElementName("backendAddressPools"),
+		"backendHTTPSettingsName"		: getSingleElementName("backendHttpSettingsCollection"),
+		"probeName"						: getSingleElementName("probes"),
+		"sslCertificateName"			: getSingleElementName("sslCertificates"),
+		"redirectConfigurationName"		: getSingleElementName("redirectConfigurations"),
+	}
+	//the keys of the maps are not stored in the gateway, the elements are imported under their names
+	var http_listeners map[string]Http_listener
+	for _, name := range elements["httpListeners"] {
+		if http_listeners == nil {
+			http_listeners = make(map[string]Http_listener)
+		}
+		http_listeners[name] = Http_listener{Name: types.String{Value: name}}
+	}
+	var request_routing_rules map[string]Request_routing_rule
+	for _, name := range elements["requestRoutingRules"] {
+		if request_routing_rules == nil {
+			request_routing_rules = make(map[string]Request_routing_rule)
+		}
+		request_routing_rules[name] = Request_routing_rule{Name: types.String{Value: name}}
+	}
+	var url_path_maps map[string]Url_path_map
+	for _, name := range elements["urlPathMaps"] {
+		if url_path_maps == nil {
+			url_path_maps = make(map[string]Url_path_map)
+		}
+		url_path_maps[name] = Url_path_map{Name: types.String{Value: name}}
+	}
+	var rewrite_rule_sets map[string]Rewrite_rule_set
+	for _, name := range elements["rewriteRuleSets"] {
+		if rewrite_rule_sets == nil {
+			rewrite_rule_sets = make(map[string]Rewrite_rule_set)
+		}
+		rewrite_rule_sets[name] = Rewrite_rule_set{Name: types.String{Value: name}}
+	}
+	var ssl_profiles map[string]Ssl_profile
+	for _, name := range elements["sslProfiles"] {
+		if ssl_profiles == nil {
+			ssl_profiles = make(map[string]Ssl_profile)
+		}
+		ssl_profiles[name] = Ssl_profile{Name: types.String{Value: name}}
+	}
+	var trusted_client_certificates map[string]Trusted_client_certificate
+	for _, name := range elements["trustedClientCertificates"] {
+		if trusted_client_certificates == nil {
+			trusted_client_certificates = make(map[string]Trusted_client_certificate)
+		}
+		trusted_client_certificates[name] = Trusted_client_certificate{Name: types.String{Value: name}}
+	}
+
+	//there is no prior state: the PFX and its password, the priority range and the options of the binding have to be set in the configuration
+	state := getBindingServiceState(r.p.AZURE_SUBSCRIPTION_ID, names_map, http_listeners, request_routing_rules, url_path_maps,
+		rewrite_rule_sets, ssl_profiles, trusted_client_certificates, Ssl_certificate{}, r.p.token.Access_token)
+	state.Backend_http_settings.Connection_draining = getConnectionDrainingState(state.Backend_http_settings.Connection_draining, nil)
+	state.Probe.Match = getProbeMatchState(state.Probe.Match, nil)
+
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
-	}*/
+	}
 }
 
 // set default values
@@ -1103,8 +2880,14 @@ func boolDefault(defaultValue bool) boolDefaultModifier {
 }
 
 // specific processing for binding service
+func getBindingServiceID(gatewayID string, bindingServiceName string) string {
+	//the binding is not an Azure resource, its ID is built from the ID of its gateway
+	return gatewayID+"/bindingServices/"+bindingServiceName
+}
 func getBindingServiceState(AZURE_SUBSCRIPTION_ID string, names_map map[string]string, http_listeners map[string]Http_listener, 
-	request_routing_rules map[string]Request_routing_rule, Access_token string) BindingService {
+	request_routing_rules map[string]Request_routing_rule, url_path_maps map[string]Url_path_map, 
+	rewrite_rule_sets map[string]Rewrite_rule_set, ssl_profiles map[string]Ssl_profile, 
+	trusted_client_certificates map[string]Trusted_client_certificate, ssl_certificate Ssl_certificate, Access_token string) BindingService {
 	
 	// Get gw from API and then update what is in state from what the API returns
 	bindingServiceName := names_map["bindingServiceName"] 
@@ -1169,6 +2952,9 @@ func getBindingServiceState(AZURE_SUBSCRIPTION_ID string, names_map map[string]s
 	sslCertificateName := names_map["sslCertificateName"] 
 	if checkSslCertificateElement(gw, sslCertificateName) {
 		sslCertificate_state = generateSslCertificateState(gw,sslCertificateName)
+		//the PFX is kept from the prior state as long as the gateway still holds its certificate
+		sslCertificate_state = getSslCertificatePFXState(sslCertificate_state, ssl_certificate, gw)
+		sslCertificate_state = getExpiryWarningDaysState(sslCertificate_state, ssl_certificate)
 	}else{
 		sslCertificate_state = Ssl_certificate{}
 	}
@@ -1188,6 +2974,8 @@ func getBindingServiceState(AZURE_SUBSCRIPTION_ID string, names_map map[string]s
 	var result BindingService
 	result = BindingService{
 		Name						: types.String{Value: bindingServiceName},
+		Id							: types.String{Value: getBindingServiceID(gw.ID, bindingServiceName)},
+		Gateway_id					: types.String{Value: gw.ID},
 		Agw_name					: types.String{Value: names_map["applicationGatewayName"]},
 		Agw_rg						: types.String{Value: names_map["resourceGroupName"]},
 		Backend_address_pool		: backendAddressPool_state,
@@ -1206,6 +2994,7 @@ func getBindingServiceState(AZURE_SUBSCRIPTION_ID string, names_map map[string]s
 		var httpListener_state Http_listener
 		if checkHTTPListenerElement(gw, value.Name.Value) {
 			httpListener_state = generateHTTPListenerState(gw,value.Name.Value)
+			httpListener_state.Firewall_policy_id = getFirewallPolicyIDState(httpListener_state.Firewall_policy_id, value.Firewall_policy_id)
 		}else{
 			httpListener_state = Http_listener{}
 		}
@@ -1228,6 +3017,70 @@ func getBindingServiceState(AZURE_SUBSCRIPTION_ID string, names_map map[string]s
 	}
 	result.Request_routing_rules = requestRoutingRules_state
 
+	// *********** Processing the URL Path Map Map *********** //
+	//check if the URL Path Map exists in the gateway, otherwise, it was removed manually
+	if url_path_maps != nil {
+		urlPathMaps_state := make(map [string]Url_path_map, len(url_path_maps))
+		for key, value := range url_path_maps {
+			var urlPathMap_state Url_path_map
+			if checkURLPathMapElement(gw, value.Name.Value) {
+				urlPathMap_state = generateURLPathMapState(gw,value.Name.Value,value.Path_rules)
+			}else{
+				urlPathMap_state = Url_path_map{}
+			}
+			urlPathMaps_state[key] = urlPathMap_state
+		}
+		result.Url_path_maps = urlPathMaps_state
+	}
+
+	// *********** Processing the Rewrite Rule Set Map *********** //
+	//check if the Rewrite Rule Set exists in the gateway, otherwise, it was removed manually
+	if rewrite_rule_sets != nil {
+		rewriteRuleSets_state := make(map [string]Rewrite_rule_set, len(rewrite_rule_sets))
+		for key, value := range rewrite_rule_sets {
+			var rewriteRuleSet_state Rewrite_rule_set
+			if checkRewriteRuleSetElement(gw, value.Name.Value) {
+				rewriteRuleSet_state = generateRewriteRuleSetState(gw,value.Name.Value,value.Rewrite_rules)
+			}else{
+				rewriteRuleSet_state = Rewrite_rule_set{}
+			}
+			rewriteRuleSets_state[key] = rewriteRuleSet_state
+		}
+		result.Rewrite_rule_sets = rewriteRuleSets_state
+	}
+
+	// *********** Processing the SSL Profile Map *********** //
+	//check if the SSL Profile exists in the gateway, otherwise, it was removed manually
+	if ssl_profiles != nil {
+		sslProfiles_state := make(map [string]Ssl_profile, len(ssl_profiles))
+		for key, value := range ssl_profiles {
+			var sslProfile_state Ssl_profile
+			if checkSslProfileElement(gw, value.Name.Value) {
+				sslProfile_state = generateSslProfileState(gw,value.Name.Value)
+			}else{
+				sslProfile_state = Ssl_profile{}
+			}
+			sslProfiles_state[key] = sslProfile_state
+		}
+		result.Ssl_profiles = sslProfiles_state
+	}
+
+	// *********** Processing the Trusted Client Certificate Map *********** //
+	//check if the Trusted Client Certificate exists in the gateway, otherwise, it was removed manually
+	if trusted_client_certificates != nil {
+		trustedClientCertificates_state := make(map [string]Trusted_client_certificate, len(trusted_client_certificates))
+		for key, value := range trusted_client_certificates {
+			var trustedClientCertificate_state Trusted_client_certificate
+			if checkTrustedClientCertificateElement(gw, value.Name.Value) {
+				trustedClientCertificate_state = generateTrustedClientCertificateState(gw,value.Name.Value,value.Data)
+			}else{
+				trustedClientCertificate_state = Trusted_client_certificate{}
+			}
+			trustedClientCertificates_state[key] = trustedClientCertificate_state
+		}
+		result.Trusted_client_certificates = trustedClientCertificates_state
+	}
+
 	return result
 }
 func checkElementName(gw ApplicationGateway, plan BindingService) ([]string,bool){
@@ -1292,6 +3145,66 @@ func checkElementName(gw ApplicationGateway, plan BindingService) ([]string,bool
 			}
 		}
 	}
+	for key, urlPathMap_plan := range plan.Url_path_maps { 
+		if checkURLPathMapElement(gw, urlPathMap_plan.Name.Value) {
+			exist = true 
+			existing_element_list = append(existing_element_list,"\n	- URL Path Map ("+key+"): "+urlPathMap_plan.Name.Value)
+		}
+	}
+	//check if the urlPathMap map contains a repetitive urlPathMap names
+	for key, urlPathMap_plan := range plan.Url_path_maps { 
+		for key1, urlPathMap_plan1 := range plan.Url_path_maps {
+			if (urlPathMap_plan.Name.Value == urlPathMap_plan1.Name.Value) && (key != key1) {
+				exist = true 
+				existing_element_list = append(existing_element_list,"\n	- URL Path Map ("+key+" and "+key1+"): "+urlPathMap_plan.Name.Value)
+			}
+		}
+	}
+	for key, rewriteRuleSet_plan := range plan.Rewrite_rule_sets { 
+		if checkRewriteRuleSetElement(gw, rewriteRuleSet_plan.Name.Value) {
+			exist = true 
+			existing_element_list = append(existing_element_list,"\n	- Rewrite Rule Set ("+key+"): "+rewriteRuleSet_plan.Name.Value)
+		}
+	}
+	//check if the rewriteRuleSet map contains a repetitive rewriteRuleSet names
+	for key, rewriteRuleSet_plan := range plan.Rewrite_rule_sets { 
+		for key1, rewriteRuleSet_plan1 := range plan.Rewrite_rule_sets {
+			if (rewriteRuleSet_plan.Name.Value == rewriteRuleSet_plan1.Name.Value) && (key != key1) {
+				exist = true 
+				existing_element_list = append(existing_element_list,"\n	- Rewrite Rule Set ("+key+" and "+key1+"): "+rewriteRuleSet_plan.Name.Value)
+			}
+		}
+	}
+	for key, sslProfile_plan := range plan.Ssl_profiles { 
+		if checkSslProfileElement(gw, sslProfile_plan.Name.Value) {
+			exist = true 
+			existing_element_list = append(existing_element_list,"\n	- SSL Profile ("+key+"): "+sslProfile_plan.Name.Value)
+		}
+	}
+	//check if the sslProfile map contains a repetitive sslProfile names
+	for key, sslProfile_plan := range plan.Ssl_profiles { 
+		for key1, sslProfile_plan1 := range plan.Ssl_profiles {
+			if (sslProfile_plan.Name.Value == sslProfile_plan1.Name.Value) && (key != key1) {
+				exist = true 
+				existing_element_list = append(existing_element_list,"\n	- SSL Profile ("+key+" and "+key1+"): "+sslProfile_plan.Name.Value)
+			}
+		}
+	}
+	for key, trustedClientCertificate_plan := range plan.Trusted_client_certificates { 
+		if checkTrustedClientCertificateElement(gw, trustedClientCertificate_plan.Name.Value) {
+			exist = true 
+			existing_element_list = append(existing_element_list,"\n	- Trusted Client Certificate ("+key+"): "+trustedClientCertificate_plan.Name.Value)
+		}
+	}
+	//check if the trustedClientCertificate map contains a repetitive trustedClientCertificate names
+	for key, trustedClientCertificate_plan := range plan.Trusted_client_certificates { 
+		for key1, trustedClientCertificate_plan1 := range plan.Trusted_client_certificates {
+			if (trustedClientCertificate_plan.Name.Value == trustedClientCertificate_plan1.Name.Value) && (key != key1) {
+				exist = true 
+				existing_element_list = append(existing_element_list,"\n	- Trusted Client Certificate ("+key+" and "+key1+"): "+trustedClientCertificate_plan.Name.Value)
+			}
+		}
+	}
 	existing_element_list = append(existing_element_list,"\n")
 	return existing_element_list,exist
 }
@@ -1317,54 +3230,68 @@ func checkPlanElementName(plan BindingService) ([]string,bool){
 	existing_element_list = append(existing_element_list,"\n")
 	return existing_element_list,exist
 }
-func generatePriority(gw ApplicationGateway, level string) int {
-	priority := 0
-	rand.Seed(time.Now().UnixNano())
-	var priorities = make([]int,len(gw.Properties.RequestRoutingRules))
+func generatePriority(gw ApplicationGateway, priority_range [2]int, reserved_priorities []int) (int, error) {
+	//the priorities used by the gw and the ones declared in the configuration are not free
+	used_priorities := make(map[int]bool, len(gw.Properties.RequestRoutingRules)+len(reserved_priorities))
 	for i := 0; i < len(gw.Properties.RequestRoutingRules); i++ {
-		priorities[i] = gw.Properties.RequestRoutingRules[i].Properties.Priority
-	}
-	good_priority :=false
-	for good_priority == false{
-		priority = rand.Intn(300-1) + 1
-		for i := 0; i < len(priorities); i++ {
-			if priority == priorities[i] {
-				good_priority = true
-			}
+		used_priorities[gw.Properties.RequestRoutingRules[i].Properties.Priority] = true
+	}
+	for i := 0; i < len(reserved_priorities); i++ {
+		used_priorities[reserved_priorities[i]] = true
+	}
+	//allocate the lowest free priority of the range
+	for priority := priority_range[0]; priority <= priority_range[1]; priority++ {
+		if !used_priorities[priority] {
+			return priority, nil
 		}
-		good_priority = !good_priority
 	}
-	return priority
+	return 0, fmt.Errorf("all the priorities between %d and %d are already used", priority_range[0], priority_range[1])
 }
 
 //Client operations
 func getGW(subscriptionId string, resourceGroupName string, applicationGatewayName string, token string) ApplicationGateway {
+	agw, code, err := readGW(subscriptionId, resourceGroupName, applicationGatewayName, token)
+	//an error response (gateway not found...) gives an empty gateway
+	if err != nil && (code == 0 || code == http.StatusOK) {
+		log.Fatalf("Call failure: %+v", err)
+	}
+	return agw
+}
+func readGW(subscriptionId string, resourceGroupName string, applicationGatewayName string, token string) (ApplicationGateway, int, error) {
+	//same as getGW, but the errors are returned with the status code (0 for a transport error), so they can be reported without stopping the provider (plan)
 	requestURI := "https://management.azure.com/subscriptions/" + subscriptionId + "/resourceGroups/" +
 		resourceGroupName + "/providers/Microsoft.Network/applicationGateways/" + applicationGatewayName + "?api-version=2021-08-01"
+	var agw ApplicationGateway
 	req, err := http.NewRequest("GET", requestURI, nil)
 	if err != nil {
-		// handle err
+		return agw, 0, err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		log.Fatalf("Call failure: %+v", err)
+		return agw, 0, err
 	}
 	defer resp.Body.Close()
+	code := resp.StatusCode
 	responseData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatal(err)
+		return agw, code, err
+	}
+	//if code != 200, the responseData contain a json that describe the error
+	if code != http.StatusOK {
+		error_json, err := PrettyString(string(responseData))
+		if err != nil {
+			error_json = string(responseData)
+		}
+		return agw, code, fmt.Errorf("status code %d: %s", code, error_json)
 	}
-
-	var agw ApplicationGateway
 	err = json.Unmarshal(responseData, &agw)
-
 	if err != nil {
-		fmt.Println(err)
+		return agw, code, fmt.Errorf("the app gateway can't be decoded: %s", err)
 	}
-	return agw
+	return agw, code, nil
 }
 func updateGW(subscriptionId string, resourceGroupName string, applicationGatewayName string, gw ApplicationGateway, token string) (ApplicationGateway, string, int) {
 	requestURI := "https://management.azure.com/subscriptions/" + subscriptionId + "/resourceGroups/" +
@@ -1381,6 +3308,10 @@ func updateGW(subscriptionId string, resourceGroupName string, applicationGatewa
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
+	//the etag of the gateway read before the modification makes the PUT fail (412) if the gateway was modified meanwhile
+	if gw.Etag != "" {
+		req.Header.Set("If-Match", gw.Etag)
+	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		log.Fatalf("Call failure: %+v", err)
@@ -1451,11 +3382,3 @@ func hasField(v interface{}, name string) bool {
 	}
 	return rv.FieldByName(name).IsValid()
 }
-func RandStringBytes(n int) string {
-    const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, n)
-    for i := range b {
-        b[i] = letterBytes[rand.Intn(len(letterBytes))]
-    }
-    return string(b)
-}
\ No newline at end of file
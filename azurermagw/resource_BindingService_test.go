@@ -0,0 +1,128 @@
+package azurermagw
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// a binding state written by the version 0 of the schema: the priorities of the request routing rules are strings
+const testBindingServiceStateV0 = `{
+	"name": "app1",
+	"application_gateway_name": "gw",
+	"application_gateway_resource_group_name": "rg",
+	"priority_range": [1000, 1999],
+	"backend_address_pool": {"name": "app1-pool", "id": "pool-id", "fqdns": ["app1.example.com"], "ip_addresses": null},
+	"backend_http_settings": {"name": "app1-settings", "id": "settings-id", "affinity_cookie_name": "", "cookie_based_affinity": "Disabled",
+		"pick_host_name_from_backend_address": true, "port": 443, "protocol": "Https", "request_timeout": 20, "probe_name": "app1-probe"},
+	"probe": {"name": "app1-probe", "id": "probe-id", "interval": 30, "protocol": "Https", "path": "/", "pick_host_name_from_backend_http_settings": true,
+		"timeout": 30, "unhealthy_threshold": 3, "minimum_servers": 0, "match": {"body": "", "status_code": ["200-399"]}},
+	"ssl_certificate": {"name": "app1-cert", "id": "cert-id", "key_vault_secret_id": "", "data": "", "password": "", "public_cert_data": ""},
+	"redirect_configuration": {"name": "app1-redirect", "id": "redirect-id", "redirect_type": "Permanent", "target_listener_name": "app1-https",
+		"target_url": "", "include_path": true, "include_query_string": true},
+	"http_listeners": {
+		"https": {"name": "app1-https", "id": "https-id", "frontend_ip_configuration_name": "public", "frontend_port_name": "port_443",
+			"require_sni": false, "protocol": "Https", "host_name": "app1.example.com", "host_names": null, "ssl_certificate_name": "app1-cert"},
+		"http": {"name": "app1-http", "id": "http-id", "frontend_ip_configuration_name": "public", "frontend_port_name": "port_80",
+			"require_sni": false, "protocol": "Http", "host_name": "app1.example.com", "host_names": null, "ssl_certificate_name": ""}
+	},
+	"request_routing_rules": {
+		"https": {"name": "app1-https", "id": "rule-https-id", "rule_type": "Basic", "priority": "1000", "http_listener_name": "app1-https",
+			"backend_address_pool_name": "app1-pool", "backend_http_settings_name": "app1-settings", "redirect_configuration_name": "",
+			"rewrite_rule_set_name": "", "url_path_map_name": ""},
+		"http": {"name": "app1-http", "id": "rule-http-id", "rule_type": "Basic", "priority": "", "http_listener_name": "app1-http",
+			"backend_address_pool_name": "", "backend_http_settings_name": "", "redirect_configuration_name": "app1-redirect",
+			"rewrite_rule_set_name": "", "url_path_map_name": ""}
+	}
+}`
+
+func upgradeTestBindingServiceState(t *testing.T, rawState *tfprotov6.RawState) tfsdk.UpgradeResourceStateResponse {
+	ctx := context.Background()
+	schema, diags := resourceBindingServiceType{}.GetSchema(ctx)
+	if diags.HasError() {
+		t.Fatalf("the schema can't be read: %v", diags)
+	}
+	upgrader, exist := resourceBindingService{}.UpgradeState(ctx)[0]
+	if !exist {
+		t.Fatal("no upgrader for the version 0 of the state")
+	}
+	req := tfsdk.UpgradeResourceStateRequest{RawState: rawState}
+	resp := tfsdk.UpgradeResourceStateResponse{
+		State: tfsdk.State{
+			Schema: schema,
+			Raw:    tftypes.NewValue(schema.TerraformType(ctx), nil),
+		},
+	}
+	upgrader.StateUpgrader(ctx, req, &resp)
+	return resp
+}
+
+func TestUpgradeBindingServiceStateV0toV1(t *testing.T) {
+	ctx := context.Background()
+	resp := upgradeTestBindingServiceState(t, &tfprotov6.RawState{JSON: []byte(testBindingServiceStateV0)})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("the state can't be upgraded: %v", resp.Diagnostics)
+	}
+	tests := []struct {
+		key      string
+		priority types.Int64
+	}{
+		{"https", types.Int64{Value: 1000}},
+		//an empty priority was never set, it becomes null
+		{"http", types.Int64{Null: true}},
+	}
+	for _, test := range tests {
+		var priority types.Int64
+		path := tftypes.NewAttributePath().WithAttributeName("request_routing_rules").WithElementKeyString(test.key).WithAttributeName("priority")
+		diags := resp.State.GetAttribute(ctx, path, &priority)
+		if diags.HasError() {
+			t.Errorf("the priority of the rule %s can't be read: %v", test.key, diags)
+			continue
+		}
+		if !priority.Equal(test.priority) {
+			t.Errorf("the priority of the rule %s is %v, want %v", test.key, priority, test.priority)
+		}
+	}
+	//the other attributes are kept as they are, the attributes added by the later versions are null
+	var name types.String
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("request_routing_rules").
+		WithElementKeyString("http").WithAttributeName("redirect_configuration_name"), &name)...)
+	var priority_range []types.Int64
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("priority_range"), &priority_range)...)
+	var adopt_existing types.Bool
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("adopt_existing"), &adopt_existing)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("the upgraded state can't be read: %v", resp.Diagnostics)
+	}
+	if name.Value != "app1-redirect" || len(priority_range) != 2 || priority_range[0].Value != 1000 || priority_range[1].Value != 1999 ||
+		!adopt_existing.Null {
+		t.Errorf("the upgraded state was modified: redirect_configuration_name = %v, priority_range = %v, adopt_existing = %v",
+			name, priority_range, adopt_existing)
+	}
+}
+
+func TestUpgradeBindingServiceStateV0toV1Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawState *tfprotov6.RawState
+	}{
+		{"no prior state", nil},
+		{"no JSON prior state", &tfprotov6.RawState{}},
+		{"invalid JSON", &tfprotov6.RawState{JSON: []byte(`{"name": `)}},
+		{"non numeric priority", &tfprotov6.RawState{JSON: []byte(`{"name": "app1", "request_routing_rules": {
+			"https": {"name": "app1-https", "priority": "high"}}}`)}},
+		{"decimal priority", &tfprotov6.RawState{JSON: []byte(`{"name": "app1", "request_routing_rules": {
+			"https": {"name": "app1-https", "priority": "10.5"}}}`)}},
+		{"attribute unknown to the schema", &tfprotov6.RawState{JSON: []byte(`{"name": "app1", "unknown_attribute": "value"}`)}},
+	}
+	for _, test := range tests {
+		resp := upgradeTestBindingServiceState(t, test.rawState)
+		if !resp.Diagnostics.HasError() {
+			t.Errorf("%s: the state was upgraded, want an error", test.name)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package azurermagw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+type resourceTrustedRootCertificateType struct{}
+
+type resourceTrustedRootCertificate struct {
+	p provider
+}
+
+// Trusted root certificate resource schema
+func (r resourceTrustedRootCertificateType) GetSchema(_ context.Context) (tfsdk.Schema, diag.Diagnostics) {
+	return tfsdk.Schema{
+		MarkdownDescription: "Manages a trusted root certificate of an existing application gateway. It can be referenced by the backend HTTP settings "+
+		"of the binding services to verify the backend servers (end-to-end TLS). Only valid for v2 SKUs.",
+		Attributes: map[string]tfsdk.Attribute{
+			"name": {
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{tfsdk.RequiresReplace()},
+				MarkdownDescription: "The name of the trusted root certificate. It has to be unique within the application gateway. Changing it forces a new resource to be created.",
+			},
+			"id": {
+				Type:     types.StringType,
+				Computed: true,
+				MarkdownDescription: "The ID of the trusted root certificate.",
+			},
+			"application_gateway_name": {
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{tfsdk.RequiresReplace()},
+				MarkdownDescription: "The name of the application gateway. Changing it forces a new resource to be created.",
+			},
+			"application_gateway_resource_group_name": {
+				Type:     types.StringType,
+				Required: true,
+				PlanModifiers: tfsdk.AttributePlanModifiers{tfsdk.RequiresReplace()},
+				MarkdownDescription: "The name of the resource group of the application gateway. Changing it forces a new resource to be created.",
+			},
+			"data": {
+				Type:     types.StringType,
+				Optional: true,
+				MarkdownDescription: "The contents of the trusted root certificate: PEM, base64 encoded PEM or base64 encoded DER (`.cer` file). "+
+				"Required if `key_vault_secret_id` is not set. The data is parsed by the provider before updating the gateway.",
+			},
+			"key_vault_secret_id": {
+				Type:     types.StringType,
+				Optional: true,
+				MarkdownDescription: "The Secret ID of the (base-64 encoded unencrypted) certificate stored in Azure KeyVault. Required if `data` is not set.",
+			},
+		},
+	}, nil
+}
+
+// New resource instance
+func (r resourceTrustedRootCertificateType) NewResource(_ context.Context, p tfsdk.Provider) (tfsdk.Resource, diag.Diagnostics) {
+	return resourceTrustedRootCertificate{
+		p: *(p.(*provider)),
+	}, nil
+}
+
+// Create a new resource
+func (r resourceTrustedRootCertificate) Create(ctx context.Context, req tfsdk.CreateResourceRequest, resp *tfsdk.CreateResourceResponse) {
+	if !r.p.configured {
+		resp.Diagnostics.AddError(
+			"Provider not configured",
+			"The provider hasn't been configured before apply, likely because it depends on an unknown value from another resource."+
+			"This leads to weird stuff happening, so we'd prefer if you didn't do that. Thanks!",
+		)
+		return
+	}
+
+	// Retrieve values from plan
+	var plan Trusted_root_certificate
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	//Get the agw (app gateway) from Azure with its Rest API
+	resourceGroupName := plan.Agw_rg.Value
+	applicationGatewayName := plan.Agw_name.Value
+	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
+
+	//the name of the trusted root certificate has to be unique in the gateway
+	if checkTrustedRootCertificateElement(gw, plan.Name.Value) {
+		resp.Diagnostics.AddError(
+			"Unable to create the trusted root certificate. The name ("+plan.Name.Value+") already exists in the application gateway "+
+			applicationGatewayName+".",
+			"Please, change the name then retry.",
+		)
+		return
+	}
+	if err, fail := checkTrustedRootCertificateConfig(plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to create the trusted root certificate. "+err,
+			"Please, change trusted root certificate configuration then retry.",
+		)
+		return
+	}
+	gw.Properties.TrustedRootCertificates = append(gw.Properties.TrustedRootCertificates, createTrustedRootCertificate(plan))
+
+	//resolve every reference of the gw before sending it, Azure only returns a generic error for a broken one
+	if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+		resp.Diagnostics.AddError(
+			"Unable to create the trusted root certificate. The application gateway would hold reference(s) to missing element(s): "+fmt.Sprint(broken_reference_list),
+			"Please, check the references of the listed elements then retry.",
+		)
+		return
+	}
+	//call the API to update the gw
+	gw_response, error_json, code := updateGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, gw, r.p.token.Access_token)
+	//the gateway was modified since it was read (etag of the If-Match header)
+	if code == 412 {
+		resp.Diagnostics.AddError(
+			"Unable to create the trusted root certificate. The app gateway "+applicationGatewayName+" was modified since it was read (API response = 412).",
+			"Please, run terraform plan and apply again, the app gateway will be read again.",
+		)
+		return
+	}
+	if code != 200 {
+		resp.Diagnostics.AddError(
+			"Unable to create the trusted root certificate. ######## API response = "+fmt.Sprint(code)+"\n"+error_json,
+			"Check the API response",
+		)
+		return
+	}
+
+	//store to the created object to the terraform state
+	result := generateTrustedRootCertificateState(gw_response, plan)
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information
+func (r resourceTrustedRootCertificate) Read(ctx context.Context, req tfsdk.ReadResourceRequest, resp *tfsdk.ReadResourceResponse) {
+	// Get current state
+	var state Trusted_root_certificate
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, state.Agw_rg.Value, state.Agw_name.Value, r.p.token.Access_token)
+	//the certificate was removed manually, so it has to be created again
+	if !checkTrustedRootCertificateElement(gw, state.Name.Value) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	state = generateTrustedRootCertificateState(gw, state)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update resource
+func (r resourceTrustedRootCertificate) Update(ctx context.Context, req tfsdk.UpdateResourceRequest, resp *tfsdk.UpdateResourceResponse) {
+	// Get plan values
+	var plan Trusted_root_certificate
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	//the name, the gateway and the resource group force a replacement, so only the certificate content is updated here
+	resourceGroupName := plan.Agw_rg.Value
+	applicationGatewayName := plan.Agw_name.Value
+	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
+	if err, fail := checkTrustedRootCertificateConfig(plan, gw); fail {
+		resp.Diagnostics.AddError(
+			"Unable to update the trusted root certificate. "+err,
+			"Please, change trusted root certificate configuration then retry.",
+		)
+		return
+	}
+	removeTrustedRootCertificateElement(&gw, plan.Name.Value)
+	gw.Properties.TrustedRootCertificates = append(gw.Properties.TrustedRootCertificates, createTrustedRootCertificate(plan))
+
+	//resolve every reference of the gw before sending it, Azure only returns a generic error for a broken one
+	if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+		resp.Diagnostics.AddError(
+			"Unable to update the trusted root certificate. The application gateway would hold reference(s) to missing element(s): "+fmt.Sprint(broken_reference_list),
+			"Please, check the references of the listed elements then retry.",
+		)
+		return
+	}
+	//call the API to update the gw
+	gw_response, error_json, code := updateGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, gw, r.p.token.Access_token)
+	//the gateway was modified since it was read (etag of the If-Match header)
+	if code == 412 {
+		resp.Diagnostics.AddError(
+			"Unable to update the trusted root certificate. The app gateway "+applicationGatewayName+" was modified since it was read (API response = 412).",
+			"Please, run terraform plan and apply again, the app gateway will be read again.",
+		)
+		return
+	}
+	if code != 200 {
+		resp.Diagnostics.AddError(
+			"Unable to update the trusted root certificate. ######## API response = "+fmt.Sprint(code)+"\n"+error_json,
+			"Check the API response",
+		)
+		return
+	}
+
+	result := generateTrustedRootCertificateState(gw_response, plan)
+	diags = resp.State.Set(ctx, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete resource
+func (r resourceTrustedRootCertificate) Delete(ctx context.Context, req tfsdk.DeleteResourceRequest, resp *tfsdk.DeleteResourceResponse) {
+	// Get current state
+	var state Trusted_root_certificate
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resourceGroupName := state.Agw_rg.Value
+	applicationGatewayName := state.Agw_name.Value
+	gw := getGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, r.p.token.Access_token)
+	//nothing to do if the certificate was already removed from the gateway
+	if checkTrustedRootCertificateElement(gw, state.Name.Value) {
+		removeTrustedRootCertificateElement(&gw, state.Name.Value)
+		//resolve every reference of the gw before sending it, Azure only returns a generic error for a broken one
+		if broken_reference_list, broken := validateGatewayReferences(gw); broken {
+			resp.Diagnostics.AddError(
+				"Unable to delete the trusted root certificate. The application gateway would hold reference(s) to missing element(s): "+fmt.Sprint(broken_reference_list),
+				"Please, check the references of the listed elements then retry.",
+			)
+			return
+		}
+		_, error_json, code := updateGW(r.p.AZURE_SUBSCRIPTION_ID, resourceGroupName, applicationGatewayName, gw, r.p.token.Access_token)
+		//the gateway was modified since it was read (etag of the If-Match header)
+		if code == 412 {
+			resp.Diagnostics.AddError(
+				"Unable to delete the trusted root certificate. The app gateway "+applicationGatewayName+" was modified since it was read (API response = 412).",
+				"Please, run terraform plan and apply again, the app gateway will be read again.",
+			)
+			return
+		}
+		if code != 200 {
+			resp.Diagnostics.AddError(
+				"Unable to delete the trusted root certificate. ######## API response = "+fmt.Sprint(code)+"\n"+error_json,
+				"Check the API response",
+			)
+			return
+		}
+	}
+
+	// Remove resource from state
+	resp.State.RemoveResource(ctx)
+}
+
+// Import resource
+func (r resourceTrustedRootCertificate) ImportState(ctx context.Context, req tfsdk.ImportResourceStateRequest, resp *tfsdk.ImportResourceStateResponse) {
+	//the ID given in the import command is the ID of the trusted root certificate:
+	// /subscriptions/<id>/resourceGroups/<rg>/providers/Microsoft.Network/applicationGateways/<gw>/trustedRootCertificates/<name>
+	idParts := strings.Split(strings.Trim(req.ID, "/"), "/")
+	if len(idParts) != 10 || !strings.EqualFold(idParts[2], "resourceGroups") || !strings.EqualFold(idParts[6], "applicationGateways") ||
+		!strings.EqualFold(idParts[8], "trustedRootCertificates") {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier. The identifier should be the ID of the trusted root certificate matching the following format: \n"+
+			"/subscriptions/<subscription_id>/resourceGroups/<resource_group>/providers/Microsoft.Network/applicationGateways/<gateway_name>/"+
+			"trustedRootCertificates/<name>",
+			"Please, check the import identifier then retry",
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_resource_group_name"),
+		idParts[3])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("application_gateway_name"), idParts[7])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tftypes.NewAttributePath().WithAttributeName("name"), idParts[9])...)
+}
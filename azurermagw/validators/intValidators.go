@@ -0,0 +1,49 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type int64BetweenValidator struct {
+	Min int64
+	Max int64
+}
+
+// Int64Between returns a validator which ensures that the integer value of the attribute is between min and max (inclusive).
+// Null and unknown values are not checked.
+func Int64Between(min int64, max int64) tfsdk.AttributeValidator {
+	return int64BetweenValidator{
+		Min: min,
+		Max: max,
+	}
+}
+
+// Description returns a plain text description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v int64BetweenValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be between %d and %d", v.Min, v.Max)
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v int64BetweenValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must be between `%d` and `%d`", v.Min, v.Max)
+}
+
+// Validate runs the logic of the validator.
+func (v int64BetweenValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var number types.Int64
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &number)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || number.Null || number.Unknown {
+		return
+	}
+	if number.Value < v.Min || number.Value > v.Max {
+		resp.Diagnostics.AddAttributeError(req.AttributePath,
+			"Invalid attribute value",
+			fmt.Sprintf("The value %d is not valid, the %s.", number.Value, v.Description(ctx)),
+		)
+	}
+}
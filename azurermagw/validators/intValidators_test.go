@@ -0,0 +1,27 @@
+package validators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestInt64Between(t *testing.T) {
+	runValidatorTests(t, Int64Between(1, 20000), []validatorTest{
+		{"null", types.Int64{Null: true}, true},
+		{"unknown", types.Int64{Unknown: true}, true},
+		{"below the min", types.Int64{Value: 0}, false},
+		{"min", types.Int64{Value: 1}, true},
+		{"between", types.Int64{Value: 300}, true},
+		{"max", types.Int64{Value: 20000}, true},
+		{"above the max", types.Int64{Value: 20001}, false},
+		{"negative", types.Int64{Value: -1}, false},
+		{"max int64", types.Int64{Value: math.MaxInt64}, false},
+	})
+	runValidatorTests(t, Int64Between(-5, -5), []validatorTest{
+		{"single value", types.Int64{Value: -5}, true},
+		{"below the single value", types.Int64{Value: -6}, false},
+		{"above the single value", types.Int64{Value: -4}, false},
+	})
+}
@@ -0,0 +1,131 @@
+package validators
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type stringInSliceValidator struct {
+	Values     []string
+	IgnoreCase bool
+}
+
+// StringInSlice returns a validator which ensures that the string value of the attribute is one of the given values.
+// Null and unknown values are not checked.
+func StringInSlice(values []string, ignoreCase bool) tfsdk.AttributeValidator {
+	return stringInSliceValidator{
+		Values:     values,
+		IgnoreCase: ignoreCase,
+	}
+}
+
+// Description returns a plain text description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v stringInSliceValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.Values, ", "))
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v stringInSliceValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: `%s`", strings.Join(v.Values, "`, `"))
+}
+
+// Validate runs the logic of the validator.
+func (v stringInSliceValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &str)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	for _, value := range v.Values {
+		if str.Value == value || (v.IgnoreCase && strings.EqualFold(str.Value, value)) {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(req.AttributePath,
+		"Invalid attribute value",
+		fmt.Sprintf("The value %q is not valid, the %s.", str.Value, v.Description(ctx)),
+	)
+}
+
+type stringLenBetweenValidator struct {
+	Min int
+	Max int
+}
+
+// StringLenBetween returns a validator which ensures that the length of the string value of the attribute is between min and max (inclusive).
+// Null and unknown values are not checked.
+func StringLenBetween(min int, max int) tfsdk.AttributeValidator {
+	return stringLenBetweenValidator{
+		Min: min,
+		Max: max,
+	}
+}
+
+// Description returns a plain text description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v stringLenBetweenValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("length must be between %d and %d", v.Min, v.Max)
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v stringLenBetweenValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("length must be between `%d` and `%d`", v.Min, v.Max)
+}
+
+// Validate runs the logic of the validator.
+func (v stringLenBetweenValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &str)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	if len(str.Value) < v.Min || len(str.Value) > v.Max {
+		resp.Diagnostics.AddAttributeError(req.AttributePath,
+			"Invalid attribute value",
+			fmt.Sprintf("The value %q is not valid, the %s.", str.Value, v.Description(ctx)),
+		)
+	}
+}
+
+type stringNotContainAnyValidator struct {
+	Chars string
+}
+
+// StringNotContainAny returns a validator which ensures that the string value of the attribute doesn't contain any of the given characters.
+// Null and unknown values are not checked.
+func StringNotContainAny(chars string) tfsdk.AttributeValidator {
+	return stringNotContainAnyValidator{
+		Chars: chars,
+	}
+}
+
+// Description returns a plain text description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v stringNotContainAnyValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must not contain any of the characters: %s", v.Chars)
+}
+
+// MarkdownDescription returns a markdown formatted description of the validator's behavior, suitable for a practitioner to understand its impact.
+func (v stringNotContainAnyValidator) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must not contain any of the characters: `%s`", v.Chars)
+}
+
+// Validate runs the logic of the validator.
+func (v stringNotContainAnyValidator) Validate(ctx context.Context, req tfsdk.ValidateAttributeRequest, resp *tfsdk.ValidateAttributeResponse) {
+	var str types.String
+	diags := tfsdk.ValueAs(ctx, req.AttributeConfig, &str)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() || str.Null || str.Unknown {
+		return
+	}
+	if strings.ContainsAny(str.Value, v.Chars) {
+		resp.Diagnostics.AddAttributeError(req.AttributePath,
+			"Invalid attribute value",
+			fmt.Sprintf("The value %q is not valid, the %s.", str.Value, v.Description(ctx)),
+		)
+	}
+}
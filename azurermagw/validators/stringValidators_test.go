@@ -0,0 +1,82 @@
+package validators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+type validatorTest struct {
+	name  string
+	value attr.Value
+	valid bool
+}
+
+func runValidatorTests(t *testing.T, validator tfsdk.AttributeValidator, tests []validatorTest) {
+	for _, test := range tests {
+		req := tfsdk.ValidateAttributeRequest{
+			AttributePath:   tftypes.NewAttributePath().WithAttributeName("test"),
+			AttributeConfig: test.value,
+		}
+		resp := tfsdk.ValidateAttributeResponse{}
+		validator.Validate(context.Background(), req, &resp)
+		if resp.Diagnostics.HasError() == test.valid {
+			t.Errorf("%s: %s: valid = %v, want %v (%v)", validator.Description(context.Background()), test.name,
+				!resp.Diagnostics.HasError(), test.valid, resp.Diagnostics)
+		}
+	}
+}
+
+func TestStringLenBetween(t *testing.T) {
+	runValidatorTests(t, StringLenBetween(2, 4), []validatorTest{
+		{"null", types.String{Null: true}, true},
+		{"unknown", types.String{Unknown: true}, true},
+		{"empty", types.String{Value: ""}, false},
+		{"below the min", types.String{Value: "a"}, false},
+		{"min", types.String{Value: "ab"}, true},
+		{"max", types.String{Value: "abcd"}, true},
+		{"above the max", types.String{Value: "abcde"}, false},
+	})
+}
+
+func TestStringNotContainAny(t *testing.T) {
+	runValidatorTests(t, StringNotContainAny(`<>%&\?/`), []validatorTest{
+		{"null", types.String{Null: true}, true},
+		{"unknown", types.String{Unknown: true}, true},
+		{"empty", types.String{Value: ""}, true},
+		{"allowed characters", types.String{Value: "app-1.web_front"}, true},
+		{"slash", types.String{Value: "app/1"}, false},
+		{"backslash", types.String{Value: `app\1`}, false},
+		{"question mark at the end", types.String{Value: "app?"}, false},
+		{"less than at the start", types.String{Value: "<app"}, false},
+		{"percent", types.String{Value: "app%20"}, false},
+	})
+}
+
+func TestStringInSlice(t *testing.T) {
+	values := []string{"Http", "Https"}
+	runValidatorTests(t, StringInSlice(values, false), []validatorTest{
+		{"null", types.String{Null: true}, true},
+		{"unknown", types.String{Unknown: true}, true},
+		{"first value", types.String{Value: "Http"}, true},
+		{"last value", types.String{Value: "Https"}, true},
+		{"other case", types.String{Value: "http"}, false},
+		{"upper case", types.String{Value: "HTTPS"}, false},
+		{"empty", types.String{Value: ""}, false},
+		{"prefix of a value", types.String{Value: "Htt"}, false},
+		{"unknown value", types.String{Value: "Ftp"}, false},
+	})
+	runValidatorTests(t, StringInSlice(values, true), []validatorTest{
+		{"null", types.String{Null: true}, true},
+		{"unknown", types.String{Unknown: true}, true},
+		{"same case", types.String{Value: "Https"}, true},
+		{"lower case", types.String{Value: "http"}, true},
+		{"upper case", types.String{Value: "HTTPS"}, true},
+		{"empty", types.String{Value: ""}, false},
+		{"unknown value", types.String{Value: "FTP"}, false},
+	})
+}
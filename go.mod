@@ -5,7 +5,9 @@ go 1.16
 require (
 	github.com/hashicorp/go-hclog v1.2.1 // indirect
 	github.com/hashicorp/terraform-plugin-docs v0.13.0
-	github.com/hashicorp/terraform-plugin-framework v0.5.0
-	github.com/mitchellh/go-testing-interface v1.14.1 // indirect
+	github.com/hashicorp/terraform-plugin-framework v0.7.0
+	github.com/hashicorp/terraform-plugin-go v0.9.0
+	github.com/vmihailenco/msgpack v4.0.4+incompatible // indirect
 	golang.org/x/net v0.0.0-20220425223048-2871e0cb64e4 // indirect
+	software.sslmate.com/src/go-pkcs12 v0.2.0
 )
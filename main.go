@@ -2,15 +2,21 @@ package main
 
 import (
 	"context"
+	"log"
 	"terraform-provider-azurermagw/azurermagw"
 
-	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
 
 // Generate the Terraform provider documentation using `tfplugindocs`:
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs
 
 func main() {	
-	tfsdk.Serve(context.Background(), azurermagw.New, tfsdk.ServeOpts{
-		Name: "azurermagw",	})
+	//the full address of the provider, as declared in the required_providers block
+	err := providerserver.Serve(context.Background(), azurermagw.New, providerserver.ServeOpts{
+		Address: "registry.terraform.io/mahmoudghorbelMG/azurermagw",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 }